@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/Homyakadze14/AFFARM_tz/internal/app"
 	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -17,39 +20,100 @@ const (
 )
 
 func main() {
-	cfg := config.MustLoad()
+	var printConfig bool
+	flag.BoolVar(&printConfig, "print-config", false, "print effective config with secrets redacted and exit")
 
-	log := setupLogger(cfg.Env)
+	configPath := config.FetchConfigPath()
+	cfg := config.MustLoadPath(configPath)
+	if printConfig {
+		if err := yaml.NewEncoder(os.Stdout).Encode(cfg.Redacted()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	level := new(slog.LevelVar)
+	level.Set(logLevel(cfg))
+	log := setupLogger(level)
 
 	application := app.Run(log, cfg)
 
+	// Configuration reload
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
 	// Graceful shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
 
-	<-stop
+loop:
+	for {
+		select {
+		case <-reload:
+			cfg = reloadConfig(log, level, application, configPath, cfg)
+		case <-stop:
+			break loop
+		}
+	}
 
 	application.Shutdown()
 	log.Info("Gracefully stopped")
 }
 
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case envLocal:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
+// reloadConfig applies the changed config file and environment and returns
+// the effective config. Changes which need a restart are logged and ignored.
+func reloadConfig(
+	log *slog.Logger,
+	level *slog.LevelVar,
+	application *app.HttpServer,
+	configPath string,
+	cfg *config.Config,
+) *config.Config {
+	log.Info("Reloading config")
+
+	next, err := config.Load(configPath)
+	if err != nil {
+		log.Error(fmt.Sprintf("Config reload rejected, keeping current config: %v", err))
+		return cfg
+	}
+
+	next, restart := cfg.Reload(next)
+	for _, name := range restart {
+		log.Warn(fmt.Sprintf("Config reload: %s changed but applies only after restart, keeping current value", name))
+	}
+
+	level.Set(logLevel(next))
+	application.Reload(next)
+	log.Info("Config reloaded")
+
+	return next
+}
+
+func logLevel(cfg *config.Config) slog.Level {
+	switch cfg.LogLevel {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+
+	switch cfg.Env {
+	case envLocal, envDev:
+		return slog.LevelDebug
 	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+		return slog.LevelInfo
 	}
 
-	return log
+	return slog.LevelInfo
+}
+
+func setupLogger(level slog.Leveler) *slog.Logger {
+	return slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}),
+	)
 }
@@ -0,0 +1,51 @@
+// Command fximport loads fiat rates from a CSV file for offline use.
+//
+//	go run ./cmd/fximport --config=config/local.env --file=rates.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"github.com/Homyakadze14/AFFARM_tz/internal/infra/fx"
+	psg "github.com/Homyakadze14/AFFARM_tz/internal/infra/postgres"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+)
+
+func main() {
+	var file string
+	flag.StringVar(&file, "file", "", "path to csv file with date,currency,rate rows")
+
+	cfg := config.MustLoad()
+	if file == "" {
+		panic("file path is empty")
+	}
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	pg, err := postgres.New(cfg.Database.URL, postgres.MaxPoolSize(cfg.Database.PoolMax))
+	if err != nil {
+		log.Error(fmt.Errorf("fximport - postgres.New: %w", err).Error())
+		os.Exit(1)
+	}
+	defer pg.Close()
+
+	svc := usecase.NewFXService(log, cfg.QuoteCurrency, psg.NewFXRateRepository(pg), fx.NewCSVSource(file))
+
+	ctx, done := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer done()
+
+	n, err := svc.Sync(ctx)
+	if err != nil {
+		log.Error(fmt.Errorf("fximport - Sync: %w", err).Error())
+		os.Exit(1)
+	}
+
+	log.Info(fmt.Sprintf("Imported %d fx rates from %s", n, file))
+}
@@ -0,0 +1,71 @@
+// Command import bulk loads historical prices from CSV or NDJSON files.
+//
+//	go run ./cmd/import --config=config/local.env --file=prices.csv
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"github.com/Homyakadze14/AFFARM_tz/internal/infra/http"
+	psg "github.com/Homyakadze14/AFFARM_tz/internal/infra/postgres"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+)
+
+func main() {
+	var file, format string
+	flag.StringVar(&file, "file", "", "path to csv or ndjson file with symbol,timestamp,price rows")
+	flag.StringVar(&format, "format", "", "file format: csv or ndjson (detected from extension by default)")
+
+	cfg := config.MustLoad()
+	if file == "" {
+		panic("file path is empty")
+	}
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+
+	log := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	pg, err := postgres.New(cfg.Database.URL, postgres.MaxPoolSize(cfg.Database.PoolMax))
+	if err != nil {
+		log.Error(fmt.Errorf("import - postgres.New: %w", err).Error())
+		os.Exit(1)
+	}
+	defer pg.Close()
+
+	f, err := os.Open(file)
+	if err != nil {
+		log.Error(fmt.Errorf("import - os.Open: %w", err).Error())
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	binanceClient := http.NewBinanceClient(log, cfg.Binance.BaseURL, cfg.QuoteCurrency, cfg.Binance.Timeout,
+		cfg.Binance.BreakerFailures, cfg.Binance.BreakerCooldown)
+	svc := usecase.NewImportService(log,
+		psg.NewCryptocurrencyRepository(pg),
+		psg.NewHistoryRepository(pg),
+		binanceClient)
+
+	report, err := svc.Import(context.Background(), format, f)
+	if err != nil {
+		log.Error(fmt.Errorf("import - Import: %w", err).Error())
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report)
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -15,104 +15,348 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/currency/add": {
-            "post": {
-                "description": "Add cryptocurrency",
-                "consumes": [
+        "/v1/admin/parser": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get workers, task queue depth and per-coin schedule, last success, last error and fetch latency",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Cryptocurrency"
+                    "Admin"
                 ],
-                "summary": "Add cryptocurrency",
-                "operationId": "AddCryptocurrency",
-                "parameters": [
-                    {
-                        "description": "Cryptocurrency add data",
-                        "name": "cryptocurrency",
-                        "in": "body",
+                "summary": "Get parser status",
+                "operationId": "GetParserStatus",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.AddCryptocurrencyRequest"
+                            "$ref": "#/definitions/dto.ParserStatusResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/parser/coins/{symbol}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Stop polling the coin without changing its tracking until resumed or the leader restarts.\nOnly the leader polls, other replicas respond with 409.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Pause coin polling",
+                "operationId": "PauseParserCoin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK"
                     },
-                    "400": {
-                        "description": "Bad Request"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     },
                     "404": {
-                        "description": "Not Found"
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     }
                 }
             }
         },
-        "/currency/price": {
+        "/v1/admin/parser/coins/{symbol}/poll": {
             "post": {
-                "description": "Get pice",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
                 ],
+                "description": "Queue an immediate poll of the coin regardless of its schedule and pause. Only the leader polls,\nother replicas respond with 409.",
                 "tags": [
-                    "Cryptocurrency"
+                    "Admin"
                 ],
-                "summary": "Get price",
-                "operationId": "GetPriceCryptocurrency",
+                "summary": "Poll coin",
+                "operationId": "PollParserCoin",
                 "parameters": [
                     {
-                        "description": "Get price data",
-                        "name": "price",
-                        "in": "body",
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/dto.PriceRequest"
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
+                }
+            }
+        },
+        "/v1/admin/parser/coins/{symbol}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Resume polling of the paused coin. Only the leader polls, other replicas respond with 409.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Resume coin polling",
+                "operationId": "ResumeParserCoin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/dto.PriceResponse"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     },
                     "404": {
-                        "description": "Not Found"
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     }
                 }
             }
         },
-        "/currency/remove": {
+        "/v1/admin/parser/pause": {
             "post": {
-                "description": "Remove cryptocurrency",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Stop polling all coins without changing their tracking until resumed or the leader restarts.\nOnly the leader polls, other replicas respond with 409.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Pause parser",
+                "operationId": "PauseParser",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/parser/resume": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Resume polling of all coins including individually paused ones. Only the leader polls,\nother replicas respond with 409.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Resume parser",
+                "operationId": "ResumeParser",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/parser/workers": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Change the number of concurrent polls of this replica until restart or change of PARSER_WORKERS",
                 "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "Cryptocurrency"
+                    "Admin"
                 ],
-                "summary": "Remove cryptocurrency",
-                "operationId": "RemoveCryptocurrency",
+                "summary": "Resize parser workers",
+                "operationId": "ResizeParser",
                 "parameters": [
                     {
-                        "description": "Cryptocurrency remove data",
-                        "name": "cryptocurrency",
+                        "description": "Worker count",
+                        "name": "workers",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.RemoveCryptocurrencyRequest"
+                            "$ref": "#/definitions/dto.ResizeParserRequest"
                         }
                     }
                 ],
@@ -121,59 +365,1793 @@ const docTemplate = `{
                         "description": "OK"
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.AddCryptocurrencyRequest": {
-            "type": "object",
-            "required": [
-                "symbol"
-            ],
-            "properties": {
-                "symbol": {
-                    "type": "string",
-                    "example": "BTC"
-                }
-            }
         },
-        "dto.PriceRequest": {
-            "type": "object",
-            "required": [
-                "symbol",
-                "timestamp"
-            ],
-            "properties": {
-                "symbol": {
-                    "type": "string",
-                    "example": "BTC"
-                },
-                "timestamp": {
-                    "type": "integer",
-                    "example": 1754578944
+        "/v1/convert": {
+            "get": {
+                "description": "Convert amount between cryptocurrencies at timestamp using cross rate via quote currency",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Convert cryptocurrency",
+                "operationId": "ConvertCryptocurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "1.5",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "SOL",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "timestamp",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "ETH",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 300,
+                        "name": "tolerance",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ConvertResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/correlation": {
+            "get": {
+                "description": "Get Pearson or Spearman correlation matrix of returns of tracked coins aligned on a common time grid",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Get correlation matrix",
+                "operationId": "GetCorrelationCryptocurrency",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1h",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pearson",
+                            "spearman"
+                        ],
+                        "type": "string",
+                        "example": "pearson",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "BTC,ETH,SOL",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754665344,
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CorrelationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/currency/add": {
+            "post": {
+                "description": "Add cryptocurrency to tracking or update its polling interval and priority.\nOmitted interval and priority are kept for an already tracked cryptocurrency.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Add cryptocurrency",
+                "operationId": "AddCryptocurrency",
+                "parameters": [
+                    {
+                        "description": "Cryptocurrency add data",
+                        "name": "cryptocurrency",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddCryptocurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/currency/price": {
+            "post": {
+                "description": "Get pice",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Get price",
+                "operationId": "GetPriceCryptocurrency",
+                "parameters": [
+                    {
+                        "description": "Get price data",
+                        "name": "price",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/currency/remove": {
+            "post": {
+                "description": "Remove cryptocurrency",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Remove cryptocurrency",
+                "operationId": "RemoveCryptocurrency",
+                "parameters": [
+                    {
+                        "description": "Cryptocurrency remove data",
+                        "name": "cryptocurrency",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RemoveCryptocurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/currency/{symbol}/indicators": {
+            "get": {
+                "description": "Get SMA, EMA, RSI or Bollinger bands series over candles of price history.\nIntervals without samples repeat the previous close. Prices are in currency,\nthe quote currency by default.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Get indicator",
+                "operationId": "GetIndicatorCryptocurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Currency of the prices, the quote currency by default.",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1h",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "sma",
+                            "ema",
+                            "rsi",
+                            "bollinger"
+                        ],
+                        "type": "string",
+                        "example": "rsi",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 14,
+                        "name": "period",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754665344,
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IndicatorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/currency/{symbol}/stats": {
+            "get": {
+                "description": "Get first/last/min/max/avg/stddev and change of price over window in currency,\nthe quote currency by default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Get price stats",
+                "operationId": "GetStatsCryptocurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Currency of the prices, the quote currency by default.",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754665344,
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/export": {
+            "get": {
+                "description": "Stream price history of symbols over time range as CSV or NDJSON, priced in currency\n(the quote currency by default).\nResponses are gzip-compressed when the client accepts it. Exports are capped by\nrows and bytes per role set by a trusted gateway; a truncated export is reported in the\nX-Export-Truncated trailer.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Export price history",
+                "operationId": "ExportPriceHistory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Currency of the prices, the quote currency by default.",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "example": "csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "BTC,ETH",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754665344,
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/import": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Bulk import symbol, timestamp, price rows from CSV or NDJSON request body.\nTimestamps are Unix seconds or RFC 3339. Unknown cryptocurrencies listed by the\nexchange are created, stored samples with another price are updated, identical\nones are skipped and invalid rows are reported by line.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Cryptocurrency"
+                ],
+                "summary": "Import price history",
+                "operationId": "ImportPriceHistory",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "example": "csv",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios": {
+            "post": {
+                "description": "Create portfolio with FIFO or average-cost accounting",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portfolio"
+                ],
+                "summary": "Create portfolio",
+                "operationId": "CreatePortfolio",
+                "parameters": [
+                    {
+                        "description": "Portfolio data",
+                        "name": "portfolio",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePortfolioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PortfolioResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/history": {
+            "get": {
+                "description": "Get portfolio value time series over window with interval step",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portfolio"
+                ],
+                "summary": "Get portfolio value history",
+                "operationId": "GetPortfolioHistory",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1h",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754665344,
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PortfolioValueResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/pnl": {
+            "get": {
+                "description": "Get realized and unrealized PnL at timestamp (now by default)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portfolio"
+                ],
+                "summary": "Get portfolio PnL",
+                "operationId": "GetPortfolioPnL",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "timestamp",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PortfolioPnLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/trades": {
+            "post": {
+                "description": "Add buy or sell trade to portfolio",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portfolio"
+                ],
+                "summary": "Add trade",
+                "operationId": "AddPortfolioTrade",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Trade data",
+                        "name": "trade",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddTradeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TradeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/portfolios/{id}/value": {
+            "get": {
+                "description": "Get portfolio value and positions at timestamp (now by default) using nearest stored prices,\nwhich must be within 5 minutes of it like in conversions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Portfolio"
+                ],
+                "summary": "Get portfolio value",
+                "operationId": "GetPortfolioValue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 1754578944,
+                        "name": "timestamp",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PortfolioValueResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/currencies/{symbol}": {
+            "put": {
+                "description": "Start tracking cryptocurrency or update its polling interval and priority",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currencies"
+                ],
+                "summary": "Track cryptocurrency",
+                "operationId": "PutCurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tracking schedule",
+                        "name": "currency",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PutCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Schedule of tracked cryptocurrency updated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CurrencyResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Cryptocurrency tracked",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CurrencyResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the cryptocurrency"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop tracking cryptocurrency, its price history is kept",
+                "tags": [
+                    "Currencies"
+                ],
+                "summary": "Untrack cryptocurrency",
+                "operationId": "DeleteCurrency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/currencies/{symbol}/price": {
+            "get": {
+                "description": "Get the price nearest to the time, the latest price without it. Historical prices are\ncacheable and revalidated with ETag.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currencies"
+                ],
+                "summary": "Get price",
+                "operationId": "GetCurrencyPrice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Cryptocurrency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "example": 1754578944,
+                        "description": "At is the unix time of the price, the latest price by default.",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached price",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PriceResponse"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Caching policy of the price"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of historical price"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Cached historical price is up to date"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AddCryptocurrencyRequest": {
+            "type": "object",
+            "required": [
+                "symbol"
+            ],
+            "properties": {
+                "interval": {
+                    "description": "Interval is the polling interval, PARSER_INTERVAL by default. Omitted\ninterval is kept for an already tracked coin, empty one resets it.",
+                    "type": "string",
+                    "example": "10s"
+                },
+                "priority": {
+                    "description": "Priority orders coins due at the same time, higher first. Omitted\npriority is kept for an already tracked coin, 0 by default.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 10
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "dto.AddTradeRequest": {
+            "type": "object",
+            "required": [
+                "price",
+                "quantity",
+                "side",
+                "symbol",
+                "timestamp"
+            ],
+            "properties": {
+                "price": {
+                    "type": "string",
+                    "example": "116894.01"
+                },
+                "quantity": {
+                    "type": "string",
+                    "example": "0.5"
+                },
+                "side": {
+                    "type": "string",
+                    "enum": [
+                        "buy",
+                        "sell"
+                    ],
+                    "example": "buy"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1754578944
+                }
+            }
+        },
+        "dto.CoinStatusResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "10s"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_error_at": {
+                    "type": "integer"
+                },
+                "last_success": {
+                    "type": "integer",
+                    "example": 1754578934
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "next_poll": {
+                    "type": "integer",
+                    "example": 1754578944
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "dto.ConvertLeg": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "string"
+                },
+                "skew_seconds": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ConvertResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ConvertLeg"
+                    }
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CorrelationResponse": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "type": "string"
+                },
+                "matrix": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.CreatePortfolioRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "accounting": {
+                    "type": "string",
+                    "enum": [
+                        "fifo",
+                        "average"
+                    ],
+                    "example": "fifo"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "main"
+                }
+            }
+        },
+        "dto.CurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "description": "Interval is the own polling interval, omitted for PARSER_INTERVAL.",
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FieldProblem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "required"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "symbol"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must be provided"
+                }
+            }
+        },
+        "dto.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ImportRowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "inserted": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.IndicatorPoint": {
+            "type": "object",
+            "properties": {
+                "lower": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "upper": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.IndicatorResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IndicatorPoint"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ParserStatusResponse": {
+            "type": "object",
+            "properties": {
+                "coins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CoinStatusResponse"
+                    }
+                },
+                "leader": {
+                    "description": "Leader is false on replicas that do not poll prices.",
+                    "type": "boolean"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "queue_capacity": {
+                    "type": "integer"
+                },
+                "queue_depth": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "workers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PortfolioPnLResponse": {
+            "type": "object",
+            "properties": {
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "realized": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "string"
+                },
+                "unrealized": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PortfolioResponse": {
+            "type": "object",
+            "properties": {
+                "accounting": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PortfolioValueResponse": {
+            "type": "object",
+            "properties": {
+                "cost_basis": {
+                    "type": "string"
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PositionResponse"
+                    }
+                },
+                "realized_pnl": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                },
+                "unrealized_pnl": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PositionResponse": {
+            "type": "object",
+            "properties": {
+                "cost_basis": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "price_at": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "realized_pnl": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "unrealized_pnl": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PriceRequest": {
+            "type": "object",
+            "required": [
+                "symbol",
+                "timestamp"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1754578944
                 }
             }
         },
         "dto.PriceResponse": {
             "type": "object",
             "properties": {
+                "currency": {
+                    "type": "string"
+                },
                 "price": {
-                    "type": "number"
+                    "type": "string",
+                    "example": "116894.01000000"
                 },
                 "timestamp": {
                     "type": "integer"
                 }
             }
         },
+        "dto.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "validation_failed"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "request validation failed"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldProblem"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/currency/price"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "6a2fb3f5-5947-4fce-937c-25b5b6ccf637"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 422
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Unprocessable Entity"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:affarm:error:validation_failed"
+                }
+            }
+        },
+        "dto.PutCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "description": "Interval is the polling interval, PARSER_INTERVAL by default.",
+                    "type": "string",
+                    "example": "10s"
+                },
+                "priority": {
+                    "description": "Priority orders coins due at the same time, higher first.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 10
+                }
+            }
+        },
         "dto.RemoveCryptocurrencyRequest": {
             "type": "object",
             "required": [
@@ -185,15 +2163,110 @@ const docTemplate = `{
                     "example": "BTC"
                 }
             }
+        },
+        "dto.ResizeParserRequest": {
+            "type": "object",
+            "required": [
+                "workers"
+            ],
+            "properties": {
+                "workers": {
+                    "type": "integer",
+                    "maximum": 256,
+                    "minimum": 1,
+                    "example": 10
+                }
+            }
+        },
+        "dto.StatsResponse": {
+            "type": "object",
+            "properties": {
+                "avg": {
+                    "type": "string"
+                },
+                "change": {
+                    "type": "string"
+                },
+                "change_percent": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "first": {
+                    "type": "string"
+                },
+                "first_at": {
+                    "type": "integer"
+                },
+                "last": {
+                    "type": "string"
+                },
+                "last_at": {
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "string"
+                },
+                "max_at": {
+                    "type": "integer"
+                },
+                "min": {
+                    "type": "string"
+                },
+                "min_at": {
+                    "type": "integer"
+                },
+                "stddev": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TradeResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "side": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin token as \"Bearer \u003cADMIN_TOKEN\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "1.0",
+	Version:          "2.0",
 	Host:             "localhost:8080",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{},
 	Title:            "AFFARM",
 	Description:      "RestAPI for AFFARM",
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -8,20 +9,33 @@ import (
 
 	"github.com/Homyakadze14/AFFARM_tz/internal/config"
 	v1 "github.com/Homyakadze14/AFFARM_tz/internal/controller/rest/v1"
+	"github.com/Homyakadze14/AFFARM_tz/internal/infra/archive"
 	"github.com/Homyakadze14/AFFARM_tz/internal/infra/background"
+	"github.com/Homyakadze14/AFFARM_tz/internal/infra/fx"
 	"github.com/Homyakadze14/AFFARM_tz/internal/infra/http"
 	psg "github.com/Homyakadze14/AFFARM_tz/internal/infra/postgres"
 	services "github.com/Homyakadze14/AFFARM_tz/internal/usecase"
 	"github.com/Homyakadze14/AFFARM_tz/pkg/httpserver"
 	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type HttpServer struct {
+	cfg *config.Config
 	s   *httpserver.Server
+	r   *v1.Router
+	cs  *services.CryptocurrencyService
 	p   *background.Parser
+	fx  *background.FXUpdater
+	ar  *background.Archiver
+	pm  *background.PartitionManager
+	le  *psg.LeaderElector
+	tl  *psg.TrackingListener
 	db  *postgres.Postgres
+	tp  *tracing.Provider
 	log *slog.Logger
 }
 
@@ -29,6 +43,9 @@ func Run(
 	log *slog.Logger,
 	cfg *config.Config,
 ) *HttpServer {
+	// Tracing
+	tp := newTracing(log, cfg)
+
 	// Database
 	pg, err := postgres.New(cfg.Database.URL, postgres.MaxPoolSize(cfg.Database.PoolMax))
 	if err != nil {
@@ -40,34 +57,217 @@ func Run(
 	cryptocurRepo := psg.NewCryptocurrencyRepository(pg)
 	trakingRepo := psg.NewTrackingRepository(pg)
 	historyRepo := psg.NewHistoryRepository(pg)
+	fxRateRepo := psg.NewFXRateRepository(pg)
+	portfolioRepo := psg.NewPortfolioRepository(pg)
+	archiveRepo := psg.NewArchiveRepository(pg)
+	partitionRepo := psg.NewPartitionRepository(pg)
+	txManager := psg.NewTxManager(pg)
+
+	// Leader election
+	leader := psg.NewLeaderElector(pg, log, cfg.Leader.Enabled, cfg.Leader.LockKey, cfg.Leader.Interval)
+	leader.Start()
 
 	// Client
-	timeout := 5 * time.Second
-	binanceClient := http.NewBinanceClient(log, timeout)
-	updateInterval := 5 * time.Second
-	parser := background.NewParser(log, updateInterval, 10, historyRepo, cryptocurRepo, binanceClient)
+	binanceClient := http.NewBinanceClient(log, cfg.Binance.BaseURL, cfg.QuoteCurrency, cfg.Binance.Timeout,
+		cfg.Binance.BreakerFailures, cfg.Binance.BreakerCooldown)
+	parser := background.NewParser(log, cfg.Parser.Interval, cfg.Parser.Workers, cfg.QuoteCurrency,
+		historyRepo, cryptocurRepo, binanceClient, leader)
+	prometheus.MustRegister(parser)
 
 	// Services
-	cryptocurService := services.NewCryptocurrencyService(log, cryptocurRepo, trakingRepo, historyRepo, binanceClient, parser)
+	var history services.HistoryStorage = historyRepo
+	var archiveService *services.ArchiveService
+	if store := newArchiveStore(log, cfg); store != nil {
+		after := time.Duration(cfg.Archive.AfterDays) * 24 * time.Hour
+		archiveService = services.NewArchiveService(log, historyRepo, archiveRepo, archive.NewParquetArchive(store), after,
+			cfg.Archive.CacheRows)
+		history = archiveService
+	}
+	fxService := services.NewFXService(log, cfg.QuoteCurrency, fxRateRepo, newFXSource(log, cfg))
+	cryptocurService := services.NewCryptocurrencyService(log, cryptocurRepo, trakingRepo, history, binanceClient,
+		fxService, parser, txManager, cfg.QuoteCurrency)
+	portfolioService := services.NewPortfolioService(log, portfolioRepo, cryptocurRepo, history, txManager)
+	importService := services.NewImportService(log, cryptocurRepo, historyRepo, binanceClient)
+	parserService := services.NewParserService(log, parser)
+	healthService := services.NewHealthService(log, pg, parser, binanceClient, cfg.Parser.HeartbeatTimeout)
 
 	// Parser
 	go func() {
 		parser.Start()
 	}()
 
+	// Tracking changes
+	trackingListener := psg.NewTrackingListener(pg, log, parser, cfg.Tracking.ResyncInterval)
+	trackingListener.Start()
+
+	// FX rates
+	var fxUpdater *background.FXUpdater
+	if cfg.FX.Source != fxSourceNone {
+		fxUpdater = background.NewFXUpdater(log, cfg.FX.UpdateInterval, fxService)
+		fxUpdater.Start()
+	}
+
+	// Partitions
+	partitionService := services.NewPartitionService(log, partitionRepo,
+		cfg.Partition.PremakeMonths, cfg.Partition.RetentionMonths, cfg.Partition.DetachOnly)
+	partitionManager := background.NewPartitionManager(log, cfg.Partition.Interval, partitionService, leader)
+	partitionManager.Start()
+
+	// Archive
+	var archiver *background.Archiver
+	if archiveService != nil {
+		archiver = background.NewArchiver(log, cfg.Archive.Interval, archiveService, leader)
+		archiver.Start()
+	}
+
 	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(log, handler, cryptocurService)
-	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
+	router := v1.NewRouter(log, handler, cfg, cryptocurService, portfolioService, importService, parserService,
+		healthService, leader)
+	httpServer := httpserver.New(handler,
+		httpserver.Port(cfg.HTTP.Port),
+		httpserver.ReadTimeout(cfg.HTTP.ReadTimeout),
+		httpserver.WriteTimeout(cfg.HTTP.WriteTimeout),
+		httpserver.ShutdownTimeout(cfg.HTTP.ShutdownTimeout),
+	)
+
+	return &HttpServer{cfg: cfg, s: httpServer, r: router, cs: cryptocurService, db: pg, log: log, p: parser,
+		fx: fxUpdater, ar: archiver, pm: partitionManager, le: leader, tl: trackingListener, tp: tp}
+}
+
+// Reload applies settings of cfg which can change without restart and
+// resyncs tracked coins of the parser with the database. Parser settings
+// are applied only when changed, so they do not override admin changes.
+func (s *HttpServer) Reload(cfg *config.Config) {
+	s.r.Reload(cfg)
+	if cfg.Parser.Interval != s.cfg.Parser.Interval {
+		s.p.SetInterval(cfg.Parser.Interval)
+	}
+	if cfg.Parser.Workers != s.cfg.Parser.Workers {
+		s.p.SetWorkers(cfg.Parser.Workers)
+	}
+	s.cfg = cfg
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.p.Resync(ctx); err != nil {
+		s.log.Error(fmt.Errorf("app - Reload - parser.Resync: %w", err).Error())
+	}
+}
+
+const (
+	fxSourceNone = "none"
+	fxSourceHTTP = "http"
+	fxSourceCSV  = "csv"
+)
+
+func newFXSource(log *slog.Logger, cfg *config.Config) services.FXSource {
+	switch cfg.FX.Source {
+	case fxSourceNone:
+		return nil
+	case fxSourceHTTP:
+		base, _ := cfg.FXBase()
+		return http.NewExchangeRateClient(log, cfg.FX.Timeout, base, cfg.FX.Currencies)
+	case fxSourceCSV:
+		return fx.NewCSVSource(cfg.FX.CSVPath)
+	default:
+		log.Error(fmt.Sprintf("app - Run - unknown fx source: %s", cfg.FX.Source))
+		os.Exit(1)
+	}
 
-	return &HttpServer{s: httpServer, db: pg, log: log, p: parser}
+	return nil
+}
+
+const (
+	tracingExporterNone = "none"
+	tracingExporterOTLP = "otlp"
+)
+
+func newTracing(log *slog.Logger, cfg *config.Config) *tracing.Provider {
+	switch cfg.Tracing.Exporter {
+	case tracingExporterNone:
+		return nil
+	case tracingExporterOTLP:
+		exporter, err := tracing.NewOTLPExporter(context.Background(), cfg.Tracing.Endpoint, cfg.Tracing.Insecure)
+		if err != nil {
+			log.Error(fmt.Errorf("app - Run - tracing.NewOTLPExporter: %w", err).Error())
+			os.Exit(1)
+		}
+
+		tp, err := tracing.New(exporter,
+			tracing.ServiceName(cfg.Tracing.ServiceName),
+			tracing.SampleRatio(cfg.Tracing.SampleRatio),
+		)
+		if err != nil {
+			log.Error(fmt.Errorf("app - Run - tracing.New: %w", err).Error())
+			os.Exit(1)
+		}
+		return tp
+	default:
+		log.Error(fmt.Sprintf("app - Run - unknown tracing exporter: %s", cfg.Tracing.Exporter))
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+const (
+	archiveBackendNone  = "none"
+	archiveBackendLocal = "local"
+	archiveBackendS3    = "s3"
+)
+
+func newArchiveStore(log *slog.Logger, cfg *config.Config) archive.Store {
+	switch cfg.Archive.Backend {
+	case archiveBackendNone:
+		return nil
+	case archiveBackendLocal:
+		return archive.NewLocalStore(cfg.Archive.Dir)
+	case archiveBackendS3:
+		s3 := cfg.Archive.S3
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		store, err := archive.NewS3Store(ctx, s3.Endpoint, s3.AccessKey, s3.SecretKey, s3.Bucket, s3.UseSSL)
+		if err != nil {
+			log.Error(fmt.Errorf("app - Run - archive.NewS3Store: %w", err).Error())
+			os.Exit(1)
+		}
+		return store
+	default:
+		log.Error(fmt.Sprintf("app - Run - unknown archive backend: %s", cfg.Archive.Backend))
+		os.Exit(1)
+	}
+
+	return nil
 }
 
 func (s *HttpServer) Shutdown() {
+	if s.tp != nil {
+		defer s.shutdownTracing()
+	}
 	defer s.db.Close()
+	defer s.le.Stop()
 	defer s.p.Stop()
+	defer s.tl.Stop()
+	defer s.pm.Stop()
+	if s.fx != nil {
+		defer s.fx.Stop()
+	}
+	if s.ar != nil {
+		defer s.ar.Stop()
+	}
 	err := s.s.Shutdown()
 	if err != nil {
 		s.log.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %w", err).Error())
 	}
 }
+
+// shutdownTracing flushes spans of stopped components.
+func (s *HttpServer) shutdownTracing() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.tp.Shutdown(ctx); err != nil {
+		s.log.Error(fmt.Errorf("app - Shutdown - tracing.Shutdown: %w", err).Error())
+	}
+}
@@ -1,63 +1,73 @@
 package common
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
+	"io"
+	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
-func ParseErr(errs error) (status int, err error) {
-	if errs == nil {
-		return http.StatusOK, nil
-	}
-
-	newErrMes := ""
-	status = 0
+// validationMessages describe failed validator tags, %s is the tag parameter.
+var validationMessages = map[string]string{
+	"required": "must be provided",
+	"email":    "must be an email",
+	"min":      "must be at least %s",
+	"max":      "must be at most %s",
+	"gt":       "must be greater than %s",
+	"gte":      "must be at least %s",
+	"lt":       "must be less than %s",
+	"lte":      "must be at most %s",
+	"oneof":    "must be one of %s",
+}
 
+// ParseErr resolves err to the application error reported to the client.
+// Validator errors become validation errors with a detail per field,
+// undecodable requests become malformed request errors and errors which
+// are not application errors become internal errors.
+func ParseErr(err error) *Error {
 	var ve validator.ValidationErrors
-	if errors.As(errs, &ve) {
-		status = http.StatusBadRequest
+	if errors.As(err, &ve) {
+		fields := make([]FieldError, 0, len(ve))
 		for _, v := range ve {
-			if v.Tag() == "required" {
-				newErrMes += fmt.Sprintf("Field %s must be provided;", v.Field())
-			}
-			if v.Tag() == "email" {
-				newErrMes += fmt.Sprintf("Field %s must contains email;", v.Field())
-			}
-			if v.Tag() == "min" {
-				newErrMes += fmt.Sprintf("Minimal lenght for field %s is %v;", v.Field(), v.Param())
-			}
-			if v.Tag() == "max" {
-				newErrMes += fmt.Sprintf("Maximum lenght for field %s is %v;", v.Field(), v.Param())
-			}
+			fields = append(fields, FieldError{Field: v.Field(), Code: v.Tag(), Message: validationMessage(v)})
 		}
+		return Validation(fields...)
 	}
 
-	switch {
-	case errors.Is(errs, ErrCryptocurrencyAlreadyExists):
-		status = http.StatusBadRequest
-		newErrMes += fmt.Sprintf("%v;", ErrCryptocurrencyAlreadyExists)
-	case errors.Is(errs, ErrCryptocurrencyNotFound):
-		status = http.StatusNotFound
-		newErrMes += fmt.Sprintf("%v;", ErrCryptocurrencyNotFound)
-	case errors.Is(errs, ErrHistoryNotFound):
-		status = http.StatusNotFound
-		newErrMes += fmt.Sprintf("%v;", ErrHistoryNotFound)
-	case errors.Is(errs, ErrTrackingAlreadyExists):
-		status = http.StatusBadRequest
-		newErrMes += fmt.Sprintf("%v;", ErrTrackingAlreadyExists)
-	case errors.Is(errs, ErrTrackingNotFound):
-		status = http.StatusNotFound
-		newErrMes += fmt.Sprintf("%v;", ErrTrackingNotFound)
-	case errors.Is(errs, ErrSymbolNotFound):
-		status = http.StatusNotFound
-		newErrMes += fmt.Sprintf("%v;", ErrSymbolNotFound)
-	case newErrMes == "":
-		status = http.StatusInternalServerError
-		newErrMes = "Internal server error"
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		return InvalidField(te.Field, "type", "must be of type "+te.Type.String())
 	}
 
-	return status, errors.New(newErrMes)
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var (
+		se *json.SyntaxError
+		ne *strconv.NumError
+		pe *time.ParseError
+	)
+	if errors.As(err, &se) || errors.As(err, &ne) || errors.As(err, &pe) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &Error{Code: CodeMalformedRequest, Status: ErrMalformedRequest.Status, Message: err.Error()}
+	}
+
+	return ErrUnexpected
+}
+
+func validationMessage(v validator.FieldError) string {
+	format, ok := validationMessages[v.Tag()]
+	if !ok {
+		return "must satisfy " + v.Tag()
+	}
+	if v.Param() == "" {
+		return format
+	}
+	return fmt.Sprintf(format, v.Param())
 }
@@ -1,14 +1,99 @@
 package common
 
-import "errors"
+import (
+	"net/http"
+	"strings"
+)
+
+// Codes of errors which are not sentinel errors.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeMalformedRequest = "malformed_request"
+	CodeInternalError    = "internal_error"
+)
+
+// Error is an application error with a stable machine-readable code and the
+// HTTP status it is reported with. Errors match by code, so errors.Is finds
+// sentinel errors through wrapping.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	// Fields lists invalid request fields of validation errors.
+	Fields []FieldError
+}
+
+// FieldError describes an invalid request field. Code is the failed rule,
+// e.g. the validator tag.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func NewError(code string, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field+" "+f.Message)
+	}
+	return e.Message + ": " + strings.Join(fields, "; ")
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Validation returns the error of a request with invalid fields.
+func Validation(fields ...FieldError) *Error {
+	return &Error{
+		Code:    CodeValidationFailed,
+		Status:  http.StatusUnprocessableEntity,
+		Message: "request validation failed",
+		Fields:  fields,
+	}
+}
+
+// InvalidField returns the validation error of a single field.
+func InvalidField(field, code, message string) *Error {
+	return Validation(FieldError{Field: field, Code: code, Message: message})
+}
 
 var (
-	ErrCryptocurrencyAlreadyExists = errors.New("cryptocurrency already exists")
-	ErrCryptocurrencyNotFound      = errors.New("cryptocurrency not found")
-	ErrTrackingAlreadyExists       = errors.New("tracking already exists")
-	ErrTrackingNotFound            = errors.New("tracking not found")
-	ErrHistoryNotFound             = errors.New("history not found")
-	ErrUnexpected                  = errors.New("unexpected error")
-	ErrBadData                     = errors.New("wrong symbol or currency")
-	ErrSymbolNotFound              = errors.New("symbol not found")
+	ErrCryptocurrencyAlreadyExists = NewError("cryptocurrency_already_exists", http.StatusConflict, "cryptocurrency already exists")
+	ErrCryptocurrencyNotFound      = NewError("cryptocurrency_not_found", http.StatusNotFound, "cryptocurrency not found")
+	ErrTrackingAlreadyExists       = NewError("tracking_already_exists", http.StatusConflict, "tracking already exists")
+	ErrTrackingNotFound            = NewError("tracking_not_found", http.StatusNotFound, "tracking not found")
+	ErrHistoryNotFound             = NewError("history_not_found", http.StatusNotFound, "history not found")
+	ErrHistoryAlreadyExists        = NewError("history_already_exists", http.StatusConflict, "history already exists")
+	ErrUnexpected                  = NewError(CodeInternalError, http.StatusInternalServerError, "unexpected error")
+	ErrBadData                     = NewError("invalid_symbol", http.StatusUnprocessableEntity, "wrong symbol or currency")
+	ErrSymbolNotFound              = NewError("symbol_not_found", http.StatusNotFound, "symbol not found")
+	ErrPriceOutOfTolerance         = NewError("price_out_of_tolerance", http.StatusUnprocessableEntity, "price sample is outside of tolerance")
+	ErrFXRateNotFound              = NewError("fx_rate_not_found", http.StatusNotFound, "fx rate not found")
+	ErrUnknownIndicator            = NewError("unknown_indicator", http.StatusUnprocessableEntity, "unknown indicator")
+	ErrWindowTooLarge              = NewError("window_too_large", http.StatusUnprocessableEntity, "time window has too many points for interval")
+	ErrPortfolioAlreadyExists      = NewError("portfolio_already_exists", http.StatusConflict, "portfolio already exists")
+	ErrPortfolioNotFound           = NewError("portfolio_not_found", http.StatusNotFound, "portfolio not found")
+	ErrInsufficientQuantity        = NewError("insufficient_quantity", http.StatusUnprocessableEntity, "sell quantity exceeds position")
+	ErrExportLimitExceeded         = NewError("export_limit_exceeded", http.StatusUnprocessableEntity, "export limit exceeded")
+	ErrArchiveConflict             = NewError("archive_conflict", http.StatusConflict, "price history changed while archiving")
+	ErrParserCoinNotFound          = NewError("parser_coin_not_found", http.StatusNotFound, "coin is not tracked by parser")
+	ErrParserQueueFull             = NewError("parser_queue_full", http.StatusServiceUnavailable, "parser task queue is full")
+	ErrParserNotLeader             = NewError("parser_not_leader", http.StatusConflict, "replica is not the parser leader, retry on the leader")
+	ErrExchangeFailed              = NewError("exchange_failed", http.StatusBadGateway, "exchange request failed")
+	ErrExchangeUnavailable         = NewError("exchange_unavailable", http.StatusServiceUnavailable, "exchange is unavailable")
+	ErrRequestTooLarge             = NewError("request_too_large", http.StatusRequestEntityTooLarge, "request body too large")
+	ErrMalformedRequest            = NewError(CodeMalformedRequest, http.StatusBadRequest, "malformed request")
+	ErrRouteNotFound               = NewError("route_not_found", http.StatusNotFound, "route not found")
+	ErrUnauthorized                = NewError("unauthorized", http.StatusUnauthorized, "missing or invalid admin token")
+	ErrAdminDisabled               = NewError("admin_disabled", http.StatusForbidden, "admin API is disabled")
 )
@@ -1,52 +1,239 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// Config is the service configuration. Fields tagged reload:"live" are
+// applied on SIGHUP, others need a restart.
 type Config struct {
-	Env            string `env:"ENV" env-default:"local"`
-	Database       DatabaseConfig
-	HTTP           HTTPConfig
-	MigrationsPath string
+	Env string `yaml:"env" env:"ENV" env-default:"local" reload:"live"`
+	// LogLevel is one of debug, info, warn or error and defaults to debug in
+	// local and dev environments and info in prod.
+	LogLevel string `yaml:"log_level" env:"LOG_LEVEL" reload:"live"`
+	// QuoteCurrency is the currency prices are fetched from Binance and stored in.
+	QuoteCurrency  string          `yaml:"quote_currency" env:"QUOTE_CURRENCY" env-default:"USDT"`
+	Database       DatabaseConfig  `yaml:"database"`
+	HTTP           HTTPConfig      `yaml:"http"`
+	Parser         ParserConfig    `yaml:"parser"`
+	Binance        BinanceConfig   `yaml:"binance"`
+	FX             FXConfig        `yaml:"fx"`
+	Export         ExportConfig    `yaml:"export"`
+	Import         ImportConfig    `yaml:"import"`
+	Archive        ArchiveConfig   `yaml:"archive"`
+	Partition      PartitionConfig `yaml:"partition"`
+	Leader         LeaderConfig    `yaml:"leader"`
+	Tracking       TrackingConfig  `yaml:"tracking"`
+	Tracing        TracingConfig   `yaml:"tracing"`
+	Admin          AdminConfig     `yaml:"admin"`
+	MigrationsPath string          `yaml:"migrations_path"`
 }
 
 type HTTPConfig struct {
-	Port string `env:"HTTP_PORT" env-required:"true"`
+	Port string `yaml:"port" env:"HTTP_PORT" env-required:"true"`
+	// FloatPrices renders prices of v1 price and convert responses, which
+	// were floats, as JSON numbers instead of strings for clients that still
+	// expect the float response.
+	FloatPrices     bool          `yaml:"float_prices" env:"HTTP_FLOAT_PRICES" env-default:"false" reload:"live"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" env-default:"5s"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" env-default:"5s"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" env-default:"3s"`
+	CORSOrigins     []string      `yaml:"cors_origins" env:"HTTP_CORS_ORIGINS" env-default:"http://localhost:5173,http://147.45.235.14:5173" reload:"live"`
+}
+
+// ParserConfig sets the polling interval of coins added without one and the
+// number of concurrent price requests. The replica is not ready when the
+// parser loop has not run for HeartbeatTimeout.
+type ParserConfig struct {
+	Interval         time.Duration `yaml:"interval" env:"PARSER_INTERVAL" env-default:"5s" reload:"live"`
+	Workers          int           `yaml:"workers" env:"PARSER_WORKERS" env-default:"10" reload:"live"`
+	HeartbeatTimeout time.Duration `yaml:"heartbeat_timeout" env:"PARSER_HEARTBEAT_TIMEOUT" env-default:"30s"`
+}
+
+// BinanceConfig sets the Binance API. Requests stop for BreakerCooldown
+// after BreakerFailures consecutive failures.
+type BinanceConfig struct {
+	BaseURL         string        `yaml:"base_url" env:"BINANCE_BASE_URL" env-default:"https://api.binance.com/api/v3"`
+	Timeout         time.Duration `yaml:"timeout" env:"BINANCE_TIMEOUT" env-default:"5s"`
+	BreakerFailures int           `yaml:"breaker_failures" env:"BINANCE_BREAKER_FAILURES" env-default:"5"`
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown" env:"BINANCE_BREAKER_COOLDOWN" env-default:"30s"`
+}
+
+type FXConfig struct {
+	// Source is one of "http", "csv" or "none". The http source supports
+	// quote currencies of fxBases only, csv rates must be against the quote
+	// currency.
+	Source         string        `yaml:"source" env:"FX_SOURCE" env-default:"none"`
+	CSVPath        string        `yaml:"csv_path" env:"FX_CSV_PATH"`
+	Currencies     []string      `yaml:"currencies" env:"FX_CURRENCIES" env-default:"EUR,RUB"`
+	UpdateInterval time.Duration `yaml:"update_interval" env:"FX_UPDATE_INTERVAL" env-default:"24h"`
+	Timeout        time.Duration `yaml:"timeout" env:"FX_TIMEOUT" env-default:"5s"`
+}
+
+// ExportConfig caps price history exports. Role limits are keyed by the value
+// of RoleHeader set by the authenticating gateway and fall back to the defaults.
+// Clients can send the header themselves, so it is only read with
+// TrustRoleHeader set when the gateway strips it from incoming requests.
+type ExportConfig struct {
+	RoleHeader      string           `yaml:"role_header" env:"EXPORT_ROLE_HEADER" env-default:"X-Role" reload:"live"`
+	TrustRoleHeader bool             `yaml:"trust_role_header" env:"EXPORT_TRUST_ROLE_HEADER" env-default:"false" reload:"live"`
+	MaxRows         int64            `yaml:"max_rows" env:"EXPORT_MAX_ROWS" env-default:"1000000" reload:"live"`
+	MaxBytes        int64            `yaml:"max_bytes" env:"EXPORT_MAX_BYTES" env-default:"104857600" reload:"live"`
+	RoleMaxRows     map[string]int64 `yaml:"role_max_rows" env:"EXPORT_ROLE_MAX_ROWS" reload:"live"`
+	RoleMaxBytes    map[string]int64 `yaml:"role_max_bytes" env:"EXPORT_ROLE_MAX_BYTES" reload:"live"`
+	WriteTimeout    time.Duration    `yaml:"write_timeout" env:"EXPORT_WRITE_TIMEOUT" env-default:"10m" reload:"live"`
+}
+
+// fxBases maps quote currencies supported by the http FX source to the fiat
+// currency their rates are fetched against. Stablecoins use the fiat they
+// are pegged to.
+var fxBases = map[string]string{
+	"USDT":  "USD",
+	"USDC":  "USD",
+	"FDUSD": "USD",
+	"TUSD":  "USD",
+	"EUR":   "EUR",
+	"TRY":   "TRY",
+	"BRL":   "BRL",
+}
+
+// FXBase returns the fiat currency the http FX source fetches rates against
+// for the quote currency, or false when the quote currency is unsupported.
+func (c *Config) FXBase() (string, bool) {
+	base, ok := fxBases[c.QuoteCurrency]
+	return base, ok
+}
+
+// Limits returns row and byte caps of role.
+func (c ExportConfig) Limits(role string) (rows, bytes int64) {
+	rows, bytes = c.MaxRows, c.MaxBytes
+	if v, ok := c.RoleMaxRows[role]; ok {
+		rows = v
+	}
+	if v, ok := c.RoleMaxBytes[role]; ok {
+		bytes = v
+	}
+	return rows, bytes
+}
+
+type ImportConfig struct {
+	MaxBytes    int64         `yaml:"max_bytes" env:"IMPORT_MAX_BYTES" env-default:"104857600" reload:"live"`
+	ReadTimeout time.Duration `yaml:"read_timeout" env:"IMPORT_READ_TIMEOUT" env-default:"10m" reload:"live"`
+}
+
+// ArchiveConfig moves price history older than AfterDays to Parquet objects
+// in a local directory or an S3-compatible bucket.
+type ArchiveConfig struct {
+	// Backend is one of "local", "s3" or "none".
+	Backend   string        `yaml:"backend" env:"ARCHIVE_BACKEND" env-default:"none"`
+	Dir       string        `yaml:"dir" env:"ARCHIVE_DIR" env-default:"archive"`
+	AfterDays int           `yaml:"after_days" env:"ARCHIVE_AFTER_DAYS" env-default:"90"`
+	Interval  time.Duration `yaml:"interval" env:"ARCHIVE_INTERVAL" env-default:"24h"`
+	// CacheRows caps samples of decoded archives kept in memory for reads.
+	CacheRows int      `yaml:"cache_rows" env:"ARCHIVE_CACHE_ROWS" env-default:"1000000"`
+	S3        S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" env:"ARCHIVE_S3_ENDPOINT" env-default:"localhost:9000"`
+	Bucket    string `yaml:"bucket" env:"ARCHIVE_S3_BUCKET" env-default:"price-archive"`
+	AccessKey string `yaml:"access_key" env:"ARCHIVE_S3_ACCESS_KEY" secret:"true"`
+	SecretKey string `yaml:"secret_key" env:"ARCHIVE_S3_SECRET_KEY" secret:"true"`
+	UseSSL    bool   `yaml:"use_ssl" env:"ARCHIVE_S3_USE_SSL" env-default:"false"`
+}
+
+// PartitionConfig controls monthly partitions of price history. Partitions
+// older than RetentionMonths are detached and dropped unless DetachOnly is
+// set; zero retention keeps them forever. The legacy partition of samples
+// stored before partitioning is never expired. With archiving enabled retention
+// should exceed ARCHIVE_AFTER_DAYS, otherwise unarchived samples are lost.
+type PartitionConfig struct {
+	PremakeMonths   int           `yaml:"premake_months" env:"PARTITION_PREMAKE_MONTHS" env-default:"3"`
+	RetentionMonths int           `yaml:"retention_months" env:"PARTITION_RETENTION_MONTHS" env-default:"0"`
+	DetachOnly      bool          `yaml:"detach_only" env:"PARTITION_DETACH_ONLY" env-default:"false"`
+	Interval        time.Duration `yaml:"interval" env:"PARTITION_INTERVAL" env-default:"24h"`
+}
+
+// LeaderConfig elects the replica running the parser with a Postgres
+// advisory lock on LockKey, shared by all replicas of the service.
+type LeaderConfig struct {
+	Enabled  bool          `yaml:"enabled" env:"LEADER_ELECTION" env-default:"true"`
+	LockKey  int64         `yaml:"lock_key" env:"LEADER_LOCK_KEY" env-default:"804741"`
+	Interval time.Duration `yaml:"interval" env:"LEADER_INTERVAL" env-default:"2s"`
+}
+
+// TrackingConfig sets how often parsers fully resync tracked coins in
+// addition to change notifications.
+type TrackingConfig struct {
+	ResyncInterval time.Duration `yaml:"resync_interval" env:"TRACKING_RESYNC_INTERVAL" env-default:"1m"`
+}
+
+// TracingConfig exports OpenTelemetry spans of HTTP requests, queries,
+// exchange calls and parser ticks over OTLP/HTTP to Endpoint.
+type TracingConfig struct {
+	// Exporter is one of "otlp" or "none".
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT" env-default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_OTLP_INSECURE" env-default:"true"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" env-default:"affarm"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// AdminConfig guards the admin API with a bearer token. The admin API is
+// disabled without a token.
+type AdminConfig struct {
+	Token string `yaml:"token" env:"ADMIN_TOKEN" secret:"true" reload:"live"`
 }
 
 type DatabaseConfig struct {
-	URL     string `env:"PG_URL" env-required:"true"`
-	PoolMax int    `env:"PG_POOL_MAX" env-required:"true"`
+	URL     string `yaml:"url" env:"PG_URL" env-required:"true" secret:"true"`
+	PoolMax int    `yaml:"pool_max" env:"PG_POOL_MAX" env-required:"true"`
 }
 
 func MustLoad() *Config {
-	configPath := fetchConfigPath()
-	if configPath == "" {
-		panic("config path is empty")
+	return MustLoadPath(FetchConfigPath())
+}
+
+func MustLoadPath(configPath string) *Config {
+	cfg, err := Load(configPath)
+	if err != nil {
+		panic(err.Error())
 	}
 
-	return MustLoadPath(configPath)
+	return cfg
 }
 
-func MustLoadPath(configPath string) *Config {
+// Load reads and validates the config file and environment. Variables of
+// .env files are exported to the environment, so variables removed from the
+// file keep their previous values on reload.
+func Load(configPath string) (*Config, error) {
+	if configPath == "" {
+		return nil, errors.New("config path is empty")
+	}
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		panic("config file does not exist: " + configPath)
+		return nil, errors.New("config file does not exist: " + configPath)
 	}
 
 	var cfg Config
 
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		panic("cannot read config: " + err.Error())
+		return nil, errors.New("cannot read config: " + err.Error())
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.New("invalid config " + configPath + ":\n" + err.Error())
 	}
 
-	return &cfg
+	return &cfg, nil
 }
 
-func fetchConfigPath() string {
+// FetchConfigPath returns the path of the --config flag or CONFIG_PATH.
+func FetchConfigPath() string {
 	var res string
 
 	flag.StringVar(&res, "config", "", "path to config file")
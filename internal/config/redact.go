@@ -0,0 +1,50 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// Redacted returns a copy of the configuration with fields tagged
+// secret:"true" masked. Only the password of secret URLs is masked, keyword
+// connection strings are masked as a whole.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	redact(reflect.ValueOf(&cp).Elem())
+	return &cp
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.Struct:
+			redact(f)
+		case f.Kind() == reflect.String && t.Field(i).Tag.Get("secret") == "true" && f.String() != "":
+			f.SetString(redactString(f.String()))
+		}
+	}
+}
+
+func redactString(s string) string {
+	if !strings.Contains(s, "://") {
+		return redacted
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return redacted
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", redacted)
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
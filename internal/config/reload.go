@@ -0,0 +1,41 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Reload returns a copy of the configuration with fields tagged
+// reload:"live" taken from next, and the names of other changed fields,
+// which keep their current values until a restart.
+func (c *Config) Reload(next *Config) (*Config, []string) {
+	cp := *c
+	var restart []string
+	reload(reflect.ValueOf(&cp).Elem(), reflect.ValueOf(next).Elem(), "", &restart)
+	return &cp, restart
+}
+
+func reload(cur, next reflect.Value, path string, restart *[]string) {
+	t := cur.Type()
+	for i := 0; i < cur.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.TrimPrefix(path+"."+field.Tag.Get("yaml"), ".")
+
+		if field.Type.Kind() == reflect.Struct {
+			reload(cur.Field(i), next.Field(i), name, restart)
+			continue
+		}
+		if reflect.DeepEqual(cur.Field(i).Interface(), next.Field(i).Interface()) {
+			continue
+		}
+
+		if field.Tag.Get("reload") == "live" {
+			cur.Field(i).Set(next.Field(i))
+			continue
+		}
+		if env := field.Tag.Get("env"); env != "" {
+			name = env
+		}
+		*restart = append(*restart, name)
+	}
+}
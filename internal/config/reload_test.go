@@ -0,0 +1,79 @@
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	cur := &Config{
+		LogLevel: "info",
+		Database: DatabaseConfig{URL: "postgres://localhost/db", PoolMax: 10},
+		HTTP:     HTTPConfig{CORSOrigins: []string{"http://localhost:5173"}},
+		Parser:   ParserConfig{Interval: 5 * time.Second, Workers: 10},
+		Archive:  ArchiveConfig{Backend: "none"},
+	}
+
+	tests := []struct {
+		name    string
+		change  func(next *Config)
+		check   func(t *testing.T, got *Config)
+		restart []string
+	}{
+		{
+			name:    "unchanged",
+			change:  func(*Config) {},
+			check:   func(*testing.T, *Config) {},
+			restart: nil,
+		},
+		{
+			name: "live fields",
+			change: func(next *Config) {
+				next.LogLevel = "debug"
+				next.HTTP.CORSOrigins = []string{"https://example.com"}
+				next.Parser.Interval = time.Second
+			},
+			check: func(t *testing.T, got *Config) {
+				if got.LogLevel != "debug" || got.Parser.Interval != time.Second ||
+					!slices.Equal(got.HTTP.CORSOrigins, []string{"https://example.com"}) {
+					t.Errorf("live fields not reloaded: %+v", got)
+				}
+			},
+		},
+		{
+			name: "restart fields kept",
+			change: func(next *Config) {
+				next.Database.PoolMax = 20
+				next.Archive.S3.Bucket = "other"
+				next.Parser.Workers = 5
+			},
+			check: func(t *testing.T, got *Config) {
+				if got.Database.PoolMax != 10 || got.Archive.S3.Bucket != "" {
+					t.Errorf("restart fields reloaded: %+v", got)
+				}
+				if got.Parser.Workers != 5 {
+					t.Errorf("got %d workers, want 5", got.Parser.Workers)
+				}
+			},
+			restart: []string{"PG_POOL_MAX", "ARCHIVE_S3_BUCKET"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := *cur
+			next.HTTP.CORSOrigins = slices.Clone(cur.HTTP.CORSOrigins)
+			tt.change(&next)
+
+			got, restart := cur.Reload(&next)
+			tt.check(t, got)
+			if !slices.Equal(restart, tt.restart) {
+				t.Errorf("got restart %v, want %v", restart, tt.restart)
+			}
+			if cur.LogLevel != "info" || cur.Parser.Workers != 10 {
+				t.Error("Reload changed the current configuration")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var currencyRe = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+// Validate checks the configuration and reports every invalid value by the
+// name of its environment variable.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, name, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+		}
+	}
+	positive := func(name string, d time.Duration) {
+		check(d > 0, name, "must be a positive duration, got %s", d)
+	}
+
+	check(slices.Contains([]string{"local", "dev", "prod"}, c.Env), "ENV",
+		"must be one of local, dev or prod, got %q", c.Env)
+	check(slices.Contains([]string{"", "debug", "info", "warn", "error"}, c.LogLevel), "LOG_LEVEL",
+		"must be one of debug, info, warn or error, got %q", c.LogLevel)
+	check(currencyRe.MatchString(c.QuoteCurrency), "QUOTE_CURRENCY",
+		"must be an upper case ticker like USDT, got %q", c.QuoteCurrency)
+
+	check(isPostgresDSN(c.Database.URL), "PG_URL", "must be a postgres:// URL or a key=value connection string")
+	check(c.Database.PoolMax > 0, "PG_POOL_MAX", "must be positive, got %d", c.Database.PoolMax)
+
+	port, err := strconv.Atoi(c.HTTP.Port)
+	check(err == nil && port > 0 && port < 65536, "HTTP_PORT", "must be a port number, got %q", c.HTTP.Port)
+	positive("HTTP_READ_TIMEOUT", c.HTTP.ReadTimeout)
+	positive("HTTP_WRITE_TIMEOUT", c.HTTP.WriteTimeout)
+	positive("HTTP_SHUTDOWN_TIMEOUT", c.HTTP.ShutdownTimeout)
+	check(len(c.HTTP.CORSOrigins) > 0, "HTTP_CORS_ORIGINS", "must not be empty")
+	for _, origin := range c.HTTP.CORSOrigins {
+		check(origin == "*" || isHTTPURL(origin), "HTTP_CORS_ORIGINS",
+			"must contain * or http(s) origins, got %q", origin)
+	}
+
+	check(c.Parser.Interval >= time.Second, "PARSER_INTERVAL", "must be at least 1s, got %s", c.Parser.Interval)
+	check(c.Parser.Workers > 0, "PARSER_WORKERS", "must be positive, got %d", c.Parser.Workers)
+	check(c.Parser.HeartbeatTimeout >= 10*time.Second, "PARSER_HEARTBEAT_TIMEOUT",
+		"must be at least 10s, got %s", c.Parser.HeartbeatTimeout)
+
+	check(isHTTPURL(c.Binance.BaseURL), "BINANCE_BASE_URL", "must be an http(s) URL, got %q", c.Binance.BaseURL)
+	positive("BINANCE_TIMEOUT", c.Binance.Timeout)
+	check(c.Binance.BreakerFailures > 0, "BINANCE_BREAKER_FAILURES",
+		"must be positive, got %d", c.Binance.BreakerFailures)
+	positive("BINANCE_BREAKER_COOLDOWN", c.Binance.BreakerCooldown)
+
+	check(slices.Contains([]string{"http", "csv", "none"}, c.FX.Source), "FX_SOURCE",
+		"must be one of http, csv or none, got %q", c.FX.Source)
+	check(c.FX.Source != "csv" || c.FX.CSVPath != "", "FX_CSV_PATH", "is required with FX_SOURCE=csv")
+	if _, ok := c.FXBase(); c.FX.Source == "http" && !ok {
+		supported := slices.Sorted(maps.Keys(fxBases))
+		check(false, "QUOTE_CURRENCY", "must be one of %s with FX_SOURCE=http, got %q",
+			strings.Join(supported, ", "), c.QuoteCurrency)
+	}
+	positive("FX_UPDATE_INTERVAL", c.FX.UpdateInterval)
+	positive("FX_TIMEOUT", c.FX.Timeout)
+
+	check(c.Export.RoleHeader != "", "EXPORT_ROLE_HEADER", "must not be empty")
+	check(c.Export.MaxRows > 0, "EXPORT_MAX_ROWS", "must be positive, got %d", c.Export.MaxRows)
+	check(c.Export.MaxBytes > 0, "EXPORT_MAX_BYTES", "must be positive, got %d", c.Export.MaxBytes)
+	positive("EXPORT_WRITE_TIMEOUT", c.Export.WriteTimeout)
+
+	check(c.Import.MaxBytes > 0, "IMPORT_MAX_BYTES", "must be positive, got %d", c.Import.MaxBytes)
+	positive("IMPORT_READ_TIMEOUT", c.Import.ReadTimeout)
+
+	check(slices.Contains([]string{"local", "s3", "none"}, c.Archive.Backend), "ARCHIVE_BACKEND",
+		"must be one of local, s3 or none, got %q", c.Archive.Backend)
+	if c.Archive.Backend != "none" {
+		check(c.Archive.AfterDays > 0, "ARCHIVE_AFTER_DAYS", "must be positive, got %d", c.Archive.AfterDays)
+		positive("ARCHIVE_INTERVAL", c.Archive.Interval)
+		check(c.Archive.CacheRows >= 0, "ARCHIVE_CACHE_ROWS", "must not be negative, got %d", c.Archive.CacheRows)
+	}
+	check(c.Archive.Backend != "local" || c.Archive.Dir != "", "ARCHIVE_DIR", "is required with ARCHIVE_BACKEND=local")
+	if c.Archive.Backend == "s3" {
+		s3 := c.Archive.S3
+		check(s3.Endpoint != "", "ARCHIVE_S3_ENDPOINT", "is required with ARCHIVE_BACKEND=s3")
+		check(s3.Bucket != "", "ARCHIVE_S3_BUCKET", "is required with ARCHIVE_BACKEND=s3")
+		check(s3.AccessKey != "", "ARCHIVE_S3_ACCESS_KEY", "is required with ARCHIVE_BACKEND=s3")
+		check(s3.SecretKey != "", "ARCHIVE_S3_SECRET_KEY", "is required with ARCHIVE_BACKEND=s3")
+	}
+
+	check(c.Partition.PremakeMonths >= 0, "PARTITION_PREMAKE_MONTHS",
+		"must not be negative, got %d", c.Partition.PremakeMonths)
+	check(c.Partition.RetentionMonths >= 0, "PARTITION_RETENTION_MONTHS",
+		"must not be negative, got %d", c.Partition.RetentionMonths)
+	positive("PARTITION_INTERVAL", c.Partition.Interval)
+
+	positive("LEADER_INTERVAL", c.Leader.Interval)
+	positive("TRACKING_RESYNC_INTERVAL", c.Tracking.ResyncInterval)
+
+	check(slices.Contains([]string{"otlp", "none"}, c.Tracing.Exporter), "TRACING_EXPORTER",
+		"must be one of otlp or none, got %q", c.Tracing.Exporter)
+	if c.Tracing.Exporter == "otlp" {
+		check(c.Tracing.Endpoint != "" && !strings.Contains(c.Tracing.Endpoint, "/"), "TRACING_OTLP_ENDPOINT",
+			"must be a host:port, got %q", c.Tracing.Endpoint)
+		check(c.Tracing.ServiceName != "", "TRACING_SERVICE_NAME", "must not be empty")
+		check(c.Tracing.SampleRatio > 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO",
+			"must be in (0, 1], got %v", c.Tracing.SampleRatio)
+	}
+
+	check(c.Admin.Token == "" || len(c.Admin.Token) >= 16, "ADMIN_TOKEN",
+		"must be empty or at least 16 characters, got %d", len(c.Admin.Token))
+
+	return errors.Join(errs...)
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isPostgresDSN(s string) bool {
+	if !strings.Contains(s, "://") {
+		return strings.Contains(s, "=")
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql")
+}
@@ -0,0 +1,12 @@
+package rest
+
+import "strings"
+
+// Currency returns the requested currency code in upper case, as currencies
+// are stored, or quote when none is requested.
+func Currency(currency, quote string) string {
+	if currency == "" {
+		return quote
+	}
+	return strings.ToUpper(currency)
+}
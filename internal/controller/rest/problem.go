@@ -0,0 +1,78 @@
+// Package rest holds helpers shared by versions of the REST API.
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RequestIDHeader carries the ID of a request, see the logging middleware.
+const RequestIDHeader = "X-Request-ID"
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:affarm:error:"
+)
+
+// HandleErr logs err and responds with its problem details. Errors of the
+// service are logged as errors, errors of the client as warnings.
+func HandleErr(c *gin.Context, log *slog.Logger, err error) {
+	appErr := common.ParseErr(err)
+	if appErr.Status >= http.StatusInternalServerError {
+		log.Error(err.Error())
+	} else {
+		log.Warn(err.Error())
+	}
+
+	WriteProblem(c, appErr)
+}
+
+// WriteProblem responds with the problem details of err.
+func WriteProblem(c *gin.Context, err *common.Error) {
+	resp := &dto.Problem{
+		Type:      problemTypePrefix + err.Code,
+		Title:     http.StatusText(err.Status),
+		Status:    err.Status,
+		Detail:    err.Message,
+		Instance:  c.Request.URL.Path,
+		Code:      err.Code,
+		RequestID: c.Writer.Header().Get(RequestIDHeader),
+	}
+	for _, f := range err.Fields {
+		resp.Errors = append(resp.Errors, dto.FieldProblem{Field: f.Field, Code: f.Code, Message: f.Message})
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.JSON(err.Status, resp)
+}
+
+// UseJSONFieldNames makes validation errors name fields as clients send
+// them, by their json or form tag.
+func UseJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "form", "uri"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+}
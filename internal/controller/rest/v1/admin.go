@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminAuth admits requests carrying the admin token as a bearer token. All
+// requests are rejected while no token is configured.
+func adminAuth(cfg *atomic.Pointer[config.AdminConfig]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := cfg.Load().Token
+		if token == "" {
+			rest.WriteProblem(c, common.ErrAdminDisabled)
+			c.Abort()
+			return
+		}
+
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			rest.WriteProblem(c, common.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type convertRoutes struct {
+	log         *slog.Logger
+	h           *usecase.CryptocurrencyService
+	floatPrices *atomic.Bool
+}
+
+func NewConvertRoutes(
+	log *slog.Logger,
+	handler *gin.RouterGroup,
+	h *usecase.CryptocurrencyService,
+	floatPrices *atomic.Bool,
+) {
+	r := &convertRoutes{log, h, floatPrices}
+
+	handler.GET("/convert", r.convert)
+}
+
+// @Summary     Convert cryptocurrency
+// @Description Convert amount between cryptocurrencies at timestamp using cross rate via quote currency
+// @ID          ConvertCryptocurrency
+// @Tags  	    Cryptocurrency
+// @Param 		convert query dto.ConvertRequest true "Convert data"
+// @Produce     json
+// @Success     200 {object} dto.ConvertResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/convert [get]
+func (r *convertRoutes) convert(c *gin.Context) {
+	const op = "convertRoutes.convert"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.ConvertRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	amount, err := decimal.NewFromString(req.Amount)
+	if err != nil || !amount.IsPositive() {
+		rest.HandleErr(c, log, common.InvalidField("amount", "decimal", "must be a positive decimal"))
+		return
+	}
+
+	tolerance := usecase.DefaultConvertTolerance
+	if req.Tolerance > 0 {
+		tolerance = time.Duration(req.Tolerance) * time.Second
+	}
+
+	// Legs may be the quote currency, which is matched in upper case.
+	conv, err := r.h.Convert(c.Request.Context(), strings.ToUpper(req.From), strings.ToUpper(req.To),
+		amount, time.Unix(req.Timestamp, 0), tolerance)
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	float := r.floatPrices.Load()
+	resp := &dto.ConvertResponse{
+		From:      conv.From,
+		To:        conv.To,
+		Quote:     conv.Quote,
+		Amount:    dto.NewPrice(conv.Amount, float),
+		Rate:      dto.NewPrice(conv.Rate, float),
+		Result:    dto.NewPrice(conv.Result, float),
+		Timestamp: conv.Timestamp.Unix(),
+		Legs:      make([]dto.ConvertLeg, 0, len(conv.Legs)),
+	}
+	for _, leg := range conv.Legs {
+		resp.Legs = append(resp.Legs, dto.ConvertLeg{
+			Symbol:      leg.Symbol,
+			Price:       dto.NewPrice(leg.Sample.Price, float),
+			Timestamp:   leg.Sample.Timestamp.Unix(),
+			SkewSeconds: int64(leg.Skew / time.Second),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
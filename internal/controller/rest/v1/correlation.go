@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/correlation"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type correlationRoutes struct {
+	log *slog.Logger
+	h   *usecase.CryptocurrencyService
+}
+
+func NewCorrelationRoutes(log *slog.Logger, handler *gin.RouterGroup, h *usecase.CryptocurrencyService) {
+	r := &correlationRoutes{log, h}
+
+	handler.GET("/correlation", r.correlation)
+}
+
+// @Summary     Get correlation matrix
+// @Description Get Pearson or Spearman correlation matrix of returns of tracked coins aligned on a common time grid
+// @ID          GetCorrelationCryptocurrency
+// @Tags  	    Cryptocurrency
+// @Param 		correlation query dto.CorrelationRequest true "Correlation parameters"
+// @Produce     json
+// @Success     200 {object} dto.CorrelationResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/correlation [get]
+func (r *correlationRoutes) correlation(c *gin.Context) {
+	const op = "correlationRoutes.correlation"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.CorrelationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	symbols := strings.Split(req.Symbols, ",")
+	if len(symbols) < 2 {
+		rest.HandleErr(c, log, common.InvalidField("symbols", "min", "must contain at least two symbols"))
+		return
+	}
+
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil || interval < time.Second {
+		rest.HandleErr(c, log, common.InvalidField("interval", "duration", "must be a duration of at least 1s"))
+		return
+	}
+
+	if req.From > req.To {
+		rest.HandleErr(c, log, common.InvalidField("to", "gtefield", "must not be before from"))
+		return
+	}
+
+	method := req.Method
+	if method == "" {
+		method = correlation.Pearson
+	}
+
+	m, err := r.h.Correlation(c.Request.Context(), symbols, method, interval,
+		time.Unix(req.From, 0), time.Unix(req.To, 0))
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.CorrelationResponse{
+		Symbols:  m.Symbols,
+		Method:   m.Method,
+		Interval: m.Interval.String(),
+		Points:   m.Points,
+		Matrix:   make([][]*float64, len(m.Matrix)),
+	}
+	for i, row := range m.Matrix {
+		resp.Matrix[i] = make([]*float64, len(row))
+		for j, v := range row {
+			resp.Matrix[i][j] = nullable(v)
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
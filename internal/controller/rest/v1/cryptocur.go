@@ -2,68 +2,90 @@ package v1
 
 import (
 	"log/slog"
+	"math"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
 	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
 	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
 	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
 
 	"github.com/gin-gonic/gin"
 )
 
 type cryptocurrencyRoutes struct {
-	log *slog.Logger
-	h   *usecase.CryptocurrencyService
+	log         *slog.Logger
+	h           *usecase.CryptocurrencyService
+	floatPrices *atomic.Bool
 }
 
-func NewHellotRoutes(log *slog.Logger, handler *gin.RouterGroup, h *usecase.CryptocurrencyService) {
-	r := &cryptocurrencyRoutes{log, h}
+func NewHellotRoutes(
+	log *slog.Logger,
+	handler *gin.RouterGroup,
+	h *usecase.CryptocurrencyService,
+	floatPrices *atomic.Bool,
+) {
+	r := &cryptocurrencyRoutes{log, h, floatPrices}
 
 	g := handler.Group("currency")
 	{
 		g.POST("/add", r.add)
 		g.POST("/remove", r.remove)
 		g.POST("/price", r.price)
+		g.GET("/:symbol/stats", r.stats)
+		g.GET("/:symbol/indicators", r.indicators)
 	}
 }
 
-func handlErr(c *gin.Context, log *slog.Logger, err error) {
-	log.Error(err.Error())
-	status, err := common.ParseErr(err)
-	c.JSON(status, gin.H{"error": err.Error()})
-}
-
 // @Summary     Add cryptocurrency
-// @Description Add cryptocurrency
+// @Description Add cryptocurrency to tracking or update its polling interval and priority.
+// @Description Omitted interval and priority are kept for an already tracked cryptocurrency.
 // @ID          AddCryptocurrency
 // @Tags  	    Cryptocurrency
 // @Accept      json
 // @Param 		cryptocurrency body dto.AddCryptocurrencyRequest false "Cryptocurrency add data"
 // @Success     200
-// @Failure     400
-// @Failure     404
-// @Failure     500
-// @Router      /currency/add [post]
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Failure     502 {object} dto.Problem
+// @Failure     503 {object} dto.Problem
+// @Router      /v1/currency/add [post]
 func (r *cryptocurrencyRoutes) add(c *gin.Context) {
 	const op = "cryptocurrencyRoutes.add"
-	log := r.log.With(
+	log := logctx.From(c.Request.Context(), r.log).With(
 		slog.String("op", op),
 	)
 
 	var req *dto.AddCryptocurrencyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		handlErr(c, log, err)
+		rest.HandleErr(c, log, err)
 		return
 	}
 
-	cr := &entity.Cryptocurrency{
-		Symbol: req.Symbol,
+	// Omitted fields keep the schedule of an already tracked coin.
+	coin := &entity.TrackedCoin{Cryptocurrency: entity.Cryptocurrency{Symbol: req.Symbol}}
+	keep := usecase.KeepSchedule{Interval: req.Interval == nil, Priority: req.Priority == nil}
+	if req.Interval != nil && *req.Interval != "" {
+		interval, err := time.ParseDuration(*req.Interval)
+		if err != nil || interval < time.Second {
+			rest.HandleErr(c, log, common.InvalidField("interval", "duration", "must be a duration of at least 1s"))
+			return
+		}
+		coin.PollInterval = interval
 	}
-	err := r.h.Add(c.Request.Context(), cr)
+	if req.Priority != nil {
+		coin.Priority = *req.Priority
+	}
+
+	_, err := r.h.Add(c.Request.Context(), coin, keep)
 	if err != nil {
-		handlErr(c, log, err)
+		rest.HandleErr(c, log, err)
 		return
 	}
 
@@ -77,19 +99,20 @@ func (r *cryptocurrencyRoutes) add(c *gin.Context) {
 // @Accept      json
 // @Param 		cryptocurrency body dto.RemoveCryptocurrencyRequest false "Cryptocurrency remove data"
 // @Success     200
-// @Failure     400
-// @Failure     404
-// @Failure     500
-// @Router      /currency/remove [post]
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/currency/remove [post]
 func (r *cryptocurrencyRoutes) remove(c *gin.Context) {
 	const op = "cryptocurrencyRoutes.remove"
-	log := r.log.With(
+	log := logctx.From(c.Request.Context(), r.log).With(
 		slog.String("op", op),
 	)
 
 	var req *dto.RemoveCryptocurrencyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		handlErr(c, log, err)
+		rest.HandleErr(c, log, err)
 		return
 	}
 
@@ -98,7 +121,7 @@ func (r *cryptocurrencyRoutes) remove(c *gin.Context) {
 	}
 	err := r.h.Remove(c.Request.Context(), cr)
 	if err != nil {
-		handlErr(c, log, err)
+		rest.HandleErr(c, log, err)
 		return
 	}
 
@@ -113,38 +136,173 @@ func (r *cryptocurrencyRoutes) remove(c *gin.Context) {
 // @Param 		price body dto.PriceRequest false "Get price data"
 // @Produce     json
 // @Success     200 {object} dto.PriceResponse
-// @Failure     400
-// @Failure     404
-// @Failure     500
-// @Router      /currency/price [post]
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/currency/price [post]
 func (r *cryptocurrencyRoutes) price(c *gin.Context) {
 	const op = "cryptocurrencyRoutes.price"
-	log := r.log.With(
+	log := logctx.From(c.Request.Context(), r.log).With(
 		slog.String("op", op),
 	)
 
 	var req *dto.PriceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		handlErr(c, log, err)
+		rest.HandleErr(c, log, err)
 		return
 	}
 
 	timestamp := time.Unix(req.Timestamp, 0)
 	if timestamp.IsZero() {
-		log.Error("invalid timestamp value", "timestamp", req.Timestamp)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timestamp value"})
+		rest.HandleErr(c, log, common.InvalidField("timestamp", "timestamp", "must be a unix time"))
 		return
 	}
 
-	hist, err := r.h.Price(c.Request.Context(), req.Symbol, timestamp)
+	currency := rest.Currency(req.Currency, r.h.Quote())
+	hist, err := r.h.Price(c.Request.Context(), req.Symbol, timestamp, currency)
 	if err != nil {
-		handlErr(c, log, err)
+		rest.HandleErr(c, log, err)
 		return
 	}
 
 	resp := &dto.PriceResponse{
-		Price:     hist.Price,
+		Price:     dto.NewPrice(hist.Price, r.floatPrices.Load()),
+		Currency:  currency,
 		Timestamp: hist.Timestamp.Unix(),
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+// @Summary     Get price stats
+// @Description Get first/last/min/max/avg/stddev and change of price over window in currency,
+// @Description the quote currency by default
+// @ID          GetStatsCryptocurrency
+// @Tags  	    Cryptocurrency
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Param 		stats query dto.StatsRequest true "Stats window"
+// @Produce     json
+// @Success     200 {object} dto.StatsResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/currency/{symbol}/stats [get]
+func (r *cryptocurrencyRoutes) stats(c *gin.Context) {
+	const op = "cryptocurrencyRoutes.stats"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.StatsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	if req.From > req.To {
+		rest.HandleErr(c, log, common.InvalidField("to", "gtefield", "must not be before from"))
+		return
+	}
+
+	symbol := c.Param("symbol")
+	currency := rest.Currency(req.Currency, r.h.Quote())
+	stats, err := r.h.Stats(c.Request.Context(), symbol, time.Unix(req.From, 0), time.Unix(req.To, 0), currency)
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.StatsResponse{
+		Symbol:        symbol,
+		Currency:      currency,
+		Count:         stats.Count,
+		First:         stats.First,
+		FirstAt:       stats.FirstAt.Unix(),
+		Last:          stats.Last,
+		LastAt:        stats.LastAt.Unix(),
+		Min:           stats.Min,
+		MinAt:         stats.MinAt.Unix(),
+		Max:           stats.Max,
+		MaxAt:         stats.MaxAt.Unix(),
+		Avg:           stats.Avg,
+		StdDev:        stats.StdDev,
+		Change:        stats.Change,
+		ChangePercent: stats.ChangePercent,
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Get indicator
+// @Description Get SMA, EMA, RSI or Bollinger bands series over candles of price history.
+// @Description Intervals without samples repeat the previous close. Prices are in currency,
+// @Description the quote currency by default.
+// @ID          GetIndicatorCryptocurrency
+// @Tags  	    Cryptocurrency
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Param 		indicator query dto.IndicatorRequest true "Indicator parameters"
+// @Produce     json
+// @Success     200 {object} dto.IndicatorResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/currency/{symbol}/indicators [get]
+func (r *cryptocurrencyRoutes) indicators(c *gin.Context) {
+	const op = "cryptocurrencyRoutes.indicators"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.IndicatorRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil || interval < time.Second {
+		rest.HandleErr(c, log, common.InvalidField("interval", "duration", "must be a duration of at least 1s"))
+		return
+	}
+
+	if req.From > req.To {
+		rest.HandleErr(c, log, common.InvalidField("to", "gtefield", "must not be before from"))
+		return
+	}
+
+	symbol := c.Param("symbol")
+	currency := rest.Currency(req.Currency, r.h.Quote())
+	series, err := r.h.Indicator(c.Request.Context(), symbol, req.Name, req.Period,
+		interval, time.Unix(req.From, 0), time.Unix(req.To, 0), currency)
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.IndicatorResponse{
+		Symbol:   symbol,
+		Currency: currency,
+		Name:     series.Name,
+		Period:   series.Period,
+		Interval: series.Interval.String(),
+		Points:   make([]dto.IndicatorPoint, 0, len(series.Points)),
+	}
+	for _, p := range series.Points {
+		resp.Points = append(resp.Points, dto.IndicatorPoint{
+			Timestamp: p.Timestamp.Unix(),
+			Value:     nullable(p.Value),
+			Upper:     nullable(p.Upper),
+			Lower:     nullable(p.Lower),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// nullable maps NaN to JSON null.
+func nullable(v float64) *float64 {
+	if math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,234 @@
+package v1
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFlushRows    = 1000
+	exportTruncated    = "X-Export-Truncated"
+)
+
+type exportRoutes struct {
+	log *slog.Logger
+	h   *usecase.CryptocurrencyService
+	cfg *atomic.Pointer[config.ExportConfig]
+}
+
+func NewExportRoutes(
+	log *slog.Logger,
+	handler *gin.RouterGroup,
+	h *usecase.CryptocurrencyService,
+	cfg *atomic.Pointer[config.ExportConfig],
+) {
+	r := &exportRoutes{log, h, cfg}
+
+	handler.GET("/export", r.export)
+}
+
+// countingWriter counts bytes written to w and rejects writes which would
+// exceed max. Row writers write each row at once, so the export stops at a
+// row boundary.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	max int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.n+int64(len(p)) > cw.max {
+		return 0, common.ErrExportLimitExceeded
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// @Summary     Export price history
+// @Description Stream price history of symbols over time range as CSV or NDJSON, priced in currency
+// @Description (the quote currency by default).
+// @Description Responses are gzip-compressed when the client accepts it. Exports are capped by
+// @Description rows and bytes per role set by a trusted gateway; a truncated export is reported in the
+// @Description X-Export-Truncated trailer.
+// @ID          ExportPriceHistory
+// @Tags  	    Cryptocurrency
+// @Param 		export query dto.ExportRequest true "Export parameters"
+// @Produce     text/csv
+// @Produce     application/x-ndjson
+// @Success     200
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/export [get]
+func (r *exportRoutes) export(c *gin.Context) {
+	const op = "exportRoutes.export"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	if req.From > req.To {
+		rest.HandleErr(c, log, common.InvalidField("to", "gtefield", "must not be before from"))
+		return
+	}
+
+	crs, err := r.h.Cryptocurrencies(c.Request.Context(), strings.Split(req.Symbols, ","))
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = exportFormatCSV
+	}
+	cfg := r.cfg.Load()
+	maxRows, maxBytes := cfg.Limits(exportRole(c, cfg))
+
+	err = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to extend write deadline! Error: %s", err))
+	}
+
+	h := c.Writer.Header()
+	h.Set("Trailer", exportTruncated)
+	h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=price_history.%s", format))
+	switch format {
+	case exportFormatCSV:
+		h.Set("Content-Type", "text/csv")
+	case exportFormatNDJSON:
+		h.Set("Content-Type", "application/x-ndjson")
+	}
+
+	var out io.Writer = c.Writer
+	flush := c.Writer.Flush
+	if strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		h.Set("Content-Encoding", "gzip")
+		h.Add("Vary", "Accept-Encoding")
+		gz := gzip.NewWriter(c.Writer)
+		defer gz.Close()
+		out = gz
+		flush = func() {
+			_ = gz.Flush()
+			c.Writer.Flush()
+		}
+	}
+	c.Status(http.StatusOK)
+
+	bw := bufio.NewWriter(out)
+	cw := &countingWriter{w: bw, max: maxBytes}
+	write := ndjsonRowWriter(cw)
+	if format == exportFormatCSV {
+		write = csvRowWriter(cw)
+	}
+
+	rows := 0
+
+	currency := rest.Currency(req.Currency, r.h.Quote())
+	n, err := r.h.Export(c.Request.Context(), crs, time.Unix(req.From, 0), time.Unix(req.To, 0), currency, maxRows,
+		func(symbol string, hist *entity.PriceHistory) error {
+			if err := write(symbol, hist); err != nil {
+				return err
+			}
+			if rows++; rows%exportFlushRows == 0 {
+				if err := bw.Flush(); err != nil {
+					return err
+				}
+				flush()
+			}
+			return nil
+		})
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	flush()
+
+	truncated := errors.Is(err, common.ErrExportLimitExceeded)
+	h.Set(exportTruncated, strconv.FormatBool(truncated))
+	if err != nil && !truncated {
+		log.Error(fmt.Sprintf("export interrupted after %d rows! Error: %s", n, err))
+	}
+}
+
+// csvRowWriter writes the header and returns a row writer. Every row is
+// encoded before it is written to w in one call.
+func csvRowWriter(w io.Writer) func(symbol string, hist *entity.PriceHistory) error {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	_ = cw.Write([]string{"symbol", "timestamp", "price"})
+	cw.Flush()
+	_, _ = w.Write(buf.Bytes())
+	buf.Reset()
+
+	return func(symbol string, hist *entity.PriceHistory) error {
+		_ = cw.Write([]string{symbol, strconv.FormatInt(hist.Timestamp.Unix(), 10), hist.Price.String()})
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		defer buf.Reset()
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+}
+
+// ndjsonRowWriter returns a row writer emitting one JSON object per line.
+// Every row is encoded before it is written to w in one call.
+func ndjsonRowWriter(w io.Writer) func(symbol string, hist *entity.PriceHistory) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+
+	return func(symbol string, hist *entity.PriceHistory) error {
+		err := enc.Encode(&dto.ExportRow{
+			Symbol:    symbol,
+			Timestamp: hist.Timestamp.Unix(),
+			Price:     hist.Price,
+		})
+		if err != nil {
+			return err
+		}
+		defer buf.Reset()
+		_, err = w.Write(buf.Bytes())
+		return err
+	}
+}
+
+// exportRole returns the role of the request set by the gateway, or an empty
+// role if the role header is not trusted.
+func exportRole(c *gin.Context, cfg *config.ExportConfig) string {
+	if !cfg.TrustRoleHeader {
+		return ""
+	}
+	return c.GetHeader(cfg.RoleHeader)
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Leader reports the role of this replica in leader election.
+type Leader interface {
+	Role() string
+}
+
+type healthRoutes struct {
+	leader Leader
+	h      *usecase.HealthService
+}
+
+// NewHealthRoutes registers probes. /healthz is liveness and only reports
+// that the process serves requests, /readyz fails while a local dependency
+// of the replica is broken and reports upstream outages as degraded.
+func NewHealthRoutes(handler *gin.Engine, leader Leader, h *usecase.HealthService) {
+	r := &healthRoutes{leader, h}
+
+	handler.GET("/healthz", r.healthz)
+	handler.GET("/readyz", r.readyz)
+}
+
+func (r *healthRoutes) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "role": r.leader.Role()})
+}
+
+func (r *healthRoutes) readyz(c *gin.Context) {
+	ready := r.h.Ready(c.Request.Context())
+
+	resp := &dto.ReadinessResponse{
+		Status:     "ready",
+		Components: make(map[string]dto.ComponentResponse, len(ready.Components)),
+	}
+	for _, comp := range ready.Components {
+		cr := dto.ComponentResponse{
+			Status:    "up",
+			State:     comp.State,
+			LatencyMS: comp.Latency.Milliseconds(),
+			Error:     comp.Error,
+		}
+		switch {
+		case !comp.Healthy:
+			cr.Status = "down"
+		case comp.Degraded:
+			cr.Status = "degraded"
+		}
+		if !comp.Heartbeat.IsZero() {
+			cr.Heartbeat = comp.Heartbeat.Unix()
+		}
+		resp.Components[comp.Name] = cr
+	}
+
+	status := http.StatusOK
+	switch {
+	case !ready.Ready:
+		resp.Status = "not_ready"
+		status = http.StatusServiceUnavailable
+	case ready.Degraded:
+		resp.Status = "degraded"
+	}
+	c.JSON(status, resp)
+}
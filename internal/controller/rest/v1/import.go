@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type importRoutes struct {
+	log *slog.Logger
+	h   *usecase.ImportService
+	cfg *atomic.Pointer[config.ImportConfig]
+}
+
+func NewImportRoutes(log *slog.Logger, handler *gin.RouterGroup, h *usecase.ImportService, cfg *atomic.Pointer[config.ImportConfig]) {
+	r := &importRoutes{log, h, cfg}
+
+	handler.POST("/import", r.importHistory)
+}
+
+// @Summary     Import price history
+// @Description Bulk import symbol, timestamp, price rows from CSV or NDJSON request body.
+// @Description Timestamps are Unix seconds or RFC 3339. Unknown cryptocurrencies listed by the
+// @Description exchange are created, stored samples with another price are updated, identical
+// @Description ones are skipped and invalid rows are reported by line.
+// @ID          ImportPriceHistory
+// @Tags  	    Cryptocurrency
+// @Security    AdminToken
+// @Accept      text/csv
+// @Accept      application/x-ndjson
+// @Param 		import query dto.ImportRequest true "Import parameters"
+// @Produce     json
+// @Success     200 {object} dto.ImportResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     413 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/import [post]
+func (r *importRoutes) importHistory(c *gin.Context) {
+	const op = "importRoutes.importHistory"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.ImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	cfg := r.cfg.Load()
+	if c.Request.ContentLength > cfg.MaxBytes {
+		rest.HandleErr(c, log, common.ErrRequestTooLarge)
+		return
+	}
+
+	err := http.NewResponseController(c.Writer).SetReadDeadline(time.Now().Add(cfg.ReadTimeout))
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to extend read deadline! Error: %s", err))
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBytes)
+
+	report, err := r.h.Import(c.Request.Context(), req.Format, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = common.ErrRequestTooLarge
+		}
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toImportResponse(report))
+}
+
+func toImportResponse(report *entity.ImportReport) *dto.ImportResponse {
+	resp := &dto.ImportResponse{
+		Rows:     report.Rows,
+		Inserted: report.Inserted,
+		Updated:  report.Updated,
+		Skipped:  report.Skipped,
+		Failed:   report.Failed,
+		Created:  report.Created,
+		Errors:   make([]dto.ImportRowError, 0, len(report.Errors)),
+	}
+	for _, e := range report.Errors {
+		resp.Errors = append(resp.Errors, dto.ImportRowError{Line: e.Line, Error: e.Error})
+	}
+	return resp
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// requestIDRe accepts request IDs of callers which are safe to log and echo.
+var requestIDRe = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// loggingMiddleware keeps the X-Request-ID of the caller or assigns a new
+// one, echoes it in the response and passes handlers a logger with the
+// request and trace IDs in the request context. When the request ends it
+// logs an access record with the status, latency and client identity.
+func loggingMiddleware(log *slog.Logger, export *atomic.Pointer[config.ExportConfig]) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(rest.RequestIDHeader)
+		if !requestIDRe.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Header(rest.RequestIDHeader, id)
+
+		reqLog := log.With(slog.String("request_id", id))
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			reqLog = reqLog.With(slog.String("trace_id", sc.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(logctx.With(c.Request.Context(), reqLog))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if role := exportRole(c, export.Load()); role != "" {
+			attrs = append(attrs, slog.String("role", role))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		reqLog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
@@ -0,0 +1,227 @@
+package v1
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type parserRoutes struct {
+	log *slog.Logger
+	h   *usecase.ParserService
+}
+
+// NewParserRoutes registers admin routes of the price parser on a group
+// guarded by the admin token. They act on the in-memory state of the parser
+// of the replica serving the request until restart. Only the leader polls,
+// so pauses and polls are rejected on other replicas.
+func NewParserRoutes(log *slog.Logger, handler *gin.RouterGroup, h *usecase.ParserService) {
+	r := &parserRoutes{log, h}
+
+	g := handler.Group("admin/parser")
+	{
+		g.GET("", r.status)
+		g.POST("/pause", r.pauseAll)
+		g.POST("/resume", r.resumeAll)
+		g.PUT("/workers", r.resize)
+		g.POST("/coins/:symbol/pause", r.pause)
+		g.POST("/coins/:symbol/resume", r.resume)
+		g.POST("/coins/:symbol/poll", r.poll)
+	}
+}
+
+// @Summary     Get parser status
+// @Description Get workers, task queue depth and per-coin schedule, last success, last error and fetch latency
+// @ID          GetParserStatus
+// @Tags  	    Admin
+// @Security    AdminToken
+// @Produce     json
+// @Success     200 {object} dto.ParserStatusResponse
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/admin/parser [get]
+func (r *parserRoutes) status(c *gin.Context) {
+	status := r.h.Status(c.Request.Context())
+
+	resp := &dto.ParserStatusResponse{
+		Leader:        status.Leader,
+		Paused:        status.Paused,
+		Workers:       status.Workers,
+		QueueDepth:    status.QueueDepth,
+		QueueCapacity: status.QueueCapacity,
+		Skipped:       status.Skipped,
+		Coins:         make([]dto.CoinStatusResponse, 0, len(status.Coins)),
+	}
+	for _, coin := range status.Coins {
+		resp.Coins = append(resp.Coins, dto.CoinStatusResponse{
+			Symbol:      coin.Symbol,
+			Interval:    coin.PollInterval.String(),
+			Priority:    coin.Priority,
+			Paused:      coin.Paused,
+			NextPoll:    coin.NextPoll.Unix(),
+			LastSuccess: unixOrZero(coin.LastSuccess),
+			LastError:   coin.LastError,
+			LastErrorAt: unixOrZero(coin.LastErrorAt),
+			Errors:      coin.Errors,
+			LatencyMS:   coin.Latency.Milliseconds(),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// @Summary     Pause parser
+// @Description Stop polling all coins without changing their tracking until resumed or the leader restarts.
+// @Description Only the leader polls, other replicas respond with 409.
+// @ID          PauseParser
+// @Tags  	    Admin
+// @Security    AdminToken
+// @Success     200
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     409 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/admin/parser/pause [post]
+func (r *parserRoutes) pauseAll(c *gin.Context) {
+	r.setPaused(c, "parserRoutes.pauseAll", "", r.h.Pause)
+}
+
+// @Summary     Resume parser
+// @Description Resume polling of all coins including individually paused ones. Only the leader polls,
+// @Description other replicas respond with 409.
+// @ID          ResumeParser
+// @Tags  	    Admin
+// @Security    AdminToken
+// @Success     200
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     409 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/admin/parser/resume [post]
+func (r *parserRoutes) resumeAll(c *gin.Context) {
+	r.setPaused(c, "parserRoutes.resumeAll", "", r.h.Resume)
+}
+
+// @Summary     Pause coin polling
+// @Description Stop polling the coin without changing its tracking until resumed or the leader restarts.
+// @Description Only the leader polls, other replicas respond with 409.
+// @ID          PauseParserCoin
+// @Tags  	    Admin
+// @Security    AdminToken
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Success     200
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     409 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/admin/parser/coins/{symbol}/pause [post]
+func (r *parserRoutes) pause(c *gin.Context) {
+	r.setPaused(c, "parserRoutes.pause", strings.ToUpper(c.Param("symbol")), r.h.Pause)
+}
+
+// @Summary     Resume coin polling
+// @Description Resume polling of the paused coin. Only the leader polls, other replicas respond with 409.
+// @ID          ResumeParserCoin
+// @Tags  	    Admin
+// @Security    AdminToken
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Success     200
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     409 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/admin/parser/coins/{symbol}/resume [post]
+func (r *parserRoutes) resume(c *gin.Context) {
+	r.setPaused(c, "parserRoutes.resume", strings.ToUpper(c.Param("symbol")), r.h.Resume)
+}
+
+func (r *parserRoutes) setPaused(c *gin.Context, op, symbol string, fn func(ctx context.Context, symbol string) error) {
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	if err := fn(c.Request.Context(), symbol); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, "")
+}
+
+// @Summary     Poll coin
+// @Description Queue an immediate poll of the coin regardless of its schedule and pause. Only the leader polls,
+// @Description other replicas respond with 409.
+// @ID          PollParserCoin
+// @Tags  	    Admin
+// @Security    AdminToken
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Success     202
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     409 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Failure     503 {object} dto.Problem
+// @Router      /v1/admin/parser/coins/{symbol}/poll [post]
+func (r *parserRoutes) poll(c *gin.Context) {
+	const op = "parserRoutes.poll"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	if err := r.h.Poll(c.Request.Context(), strings.ToUpper(c.Param("symbol"))); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, "")
+}
+
+// @Summary     Resize parser workers
+// @Description Change the number of concurrent polls of this replica until restart or change of PARSER_WORKERS
+// @ID          ResizeParser
+// @Tags  	    Admin
+// @Security    AdminToken
+// @Accept      json
+// @Param 		workers body dto.ResizeParserRequest true "Worker count"
+// @Success     200
+// @Failure     400 {object} dto.Problem
+// @Failure     401 {object} dto.Problem
+// @Failure     403 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/admin/parser/workers [put]
+func (r *parserRoutes) resize(c *gin.Context) {
+	const op = "parserRoutes.resize"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.ResizeParserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	r.h.Resize(c.Request.Context(), req.Workers)
+
+	c.JSON(http.StatusOK, "")
+}
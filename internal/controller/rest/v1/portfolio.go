@@ -0,0 +1,343 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+const sideSell = "sell"
+
+type portfolioRoutes struct {
+	log *slog.Logger
+	h   *usecase.PortfolioService
+}
+
+func NewPortfolioRoutes(log *slog.Logger, handler *gin.RouterGroup, h *usecase.PortfolioService) {
+	r := &portfolioRoutes{log, h}
+
+	g := handler.Group("portfolios")
+	{
+		g.POST("", r.create)
+		g.POST("/:id/trades", r.addTrade)
+		g.GET("/:id/value", r.value)
+		g.GET("/:id/history", r.history)
+		g.GET("/:id/pnl", r.pnl)
+	}
+}
+
+// portfolioID parses the id path parameter and writes a validation problem on failure.
+func portfolioID(c *gin.Context, log *slog.Logger) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		rest.HandleErr(c, log, common.InvalidField("id", "number", "must be a positive integer"))
+		return 0, false
+	}
+	return id, true
+}
+
+// @Summary     Create portfolio
+// @Description Create portfolio with FIFO or average-cost accounting
+// @ID          CreatePortfolio
+// @Tags  	    Portfolio
+// @Accept      json
+// @Param 		portfolio body dto.CreatePortfolioRequest true "Portfolio data"
+// @Produce     json
+// @Success     200 {object} dto.PortfolioResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     409 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/portfolios [post]
+func (r *portfolioRoutes) create(c *gin.Context) {
+	const op = "portfolioRoutes.create"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.CreatePortfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	accounting := req.Accounting
+	if accounting == "" {
+		accounting = entity.AccountingFIFO
+	}
+
+	p, err := r.h.Create(c.Request.Context(), &entity.Portfolio{
+		Name:       req.Name,
+		Accounting: accounting,
+	})
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.PortfolioResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Accounting: p.Accounting,
+		CreatedAt:  p.CreatedAt.Unix(),
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Add trade
+// @Description Add buy or sell trade to portfolio
+// @ID          AddPortfolioTrade
+// @Tags  	    Portfolio
+// @Accept      json
+// @Param 		id path int true "Portfolio id"
+// @Param 		trade body dto.AddTradeRequest true "Trade data"
+// @Produce     json
+// @Success     200 {object} dto.TradeResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/portfolios/{id}/trades [post]
+func (r *portfolioRoutes) addTrade(c *gin.Context) {
+	const op = "portfolioRoutes.addTrade"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	id, ok := portfolioID(c, log)
+	if !ok {
+		return
+	}
+
+	var req dto.AddTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	qty, err := decimal.NewFromString(req.Quantity)
+	if err != nil || !qty.IsPositive() {
+		rest.HandleErr(c, log, common.InvalidField("quantity", "decimal", "must be a positive decimal"))
+		return
+	}
+
+	price, err := decimal.NewFromString(req.Price)
+	if err != nil || price.IsNegative() {
+		rest.HandleErr(c, log, common.InvalidField("price", "decimal", "must be a non-negative decimal"))
+		return
+	}
+
+	if req.Side == sideSell {
+		qty = qty.Neg()
+	}
+
+	t, err := r.h.AddTrade(c.Request.Context(), &entity.Trade{
+		PortfolioID: id,
+		Symbol:      req.Symbol,
+		Quantity:    qty,
+		Price:       price,
+		Timestamp:   time.Unix(req.Timestamp, 0),
+	})
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.TradeResponse{
+		ID:        t.ID,
+		Symbol:    t.Symbol,
+		Side:      req.Side,
+		Quantity:  t.Quantity.Abs(),
+		Price:     t.Price,
+		Timestamp: t.Timestamp.Unix(),
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Get portfolio value
+// @Description Get portfolio value and positions at timestamp (now by default) using nearest stored prices,
+// @Description which must be within 5 minutes of it like in conversions
+// @ID          GetPortfolioValue
+// @Tags  	    Portfolio
+// @Param 		id path int true "Portfolio id"
+// @Param 		value query dto.PortfolioValueRequest false "Valuation time"
+// @Produce     json
+// @Success     200 {object} dto.PortfolioValueResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/portfolios/{id}/value [get]
+func (r *portfolioRoutes) value(c *gin.Context) {
+	const op = "portfolioRoutes.value"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	id, ok := portfolioID(c, log)
+	if !ok {
+		return
+	}
+
+	var req dto.PortfolioValueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	at := time.Now()
+	if req.Timestamp != 0 {
+		at = time.Unix(req.Timestamp, 0)
+	}
+
+	val, err := r.h.Value(c.Request.Context(), id, at)
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPortfolioValueResponse(val))
+}
+
+// @Summary     Get portfolio value history
+// @Description Get portfolio value time series over window with interval step
+// @ID          GetPortfolioHistory
+// @Tags  	    Portfolio
+// @Param 		id path int true "Portfolio id"
+// @Param 		history query dto.PortfolioHistoryRequest true "History window"
+// @Produce     json
+// @Success     200 {array} dto.PortfolioValueResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/portfolios/{id}/history [get]
+func (r *portfolioRoutes) history(c *gin.Context) {
+	const op = "portfolioRoutes.history"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	id, ok := portfolioID(c, log)
+	if !ok {
+		return
+	}
+
+	var req dto.PortfolioHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil || interval < time.Second {
+		rest.HandleErr(c, log, common.InvalidField("interval", "duration", "must be a duration of at least 1s"))
+		return
+	}
+
+	if req.From > req.To {
+		rest.HandleErr(c, log, common.InvalidField("to", "gtefield", "must not be before from"))
+		return
+	}
+
+	vals, err := r.h.History(c.Request.Context(), id, time.Unix(req.From, 0), time.Unix(req.To, 0), interval)
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := make([]*dto.PortfolioValueResponse, 0, len(vals))
+	for i := range vals {
+		resp = append(resp, toPortfolioValueResponse(&vals[i]))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// @Summary     Get portfolio PnL
+// @Description Get realized and unrealized PnL at timestamp (now by default)
+// @ID          GetPortfolioPnL
+// @Tags  	    Portfolio
+// @Param 		id path int true "Portfolio id"
+// @Param 		pnl query dto.PortfolioValueRequest false "Valuation time"
+// @Produce     json
+// @Success     200 {object} dto.PortfolioPnLResponse
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v1/portfolios/{id}/pnl [get]
+func (r *portfolioRoutes) pnl(c *gin.Context) {
+	const op = "portfolioRoutes.pnl"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	id, ok := portfolioID(c, log)
+	if !ok {
+		return
+	}
+
+	var req dto.PortfolioValueRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	at := time.Now()
+	if req.Timestamp != 0 {
+		at = time.Unix(req.Timestamp, 0)
+	}
+
+	val, err := r.h.Value(c.Request.Context(), id, at)
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.PortfolioPnLResponse{
+		PortfolioID: val.PortfolioID,
+		Timestamp:   val.Timestamp.Unix(),
+		Realized:    val.RealizedPnL,
+		Unrealized:  val.UnrealizedPnL,
+		Total:       val.RealizedPnL.Add(val.UnrealizedPnL),
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toPortfolioValueResponse(val *entity.PortfolioValuation) *dto.PortfolioValueResponse {
+	resp := &dto.PortfolioValueResponse{
+		PortfolioID:   val.PortfolioID,
+		Timestamp:     val.Timestamp.Unix(),
+		Value:         val.Value,
+		CostBasis:     val.CostBasis,
+		RealizedPnL:   val.RealizedPnL,
+		UnrealizedPnL: val.UnrealizedPnL,
+	}
+	for _, p := range val.Positions {
+		pos := dto.PositionResponse{
+			Symbol:        p.Symbol,
+			Quantity:      p.Quantity,
+			CostBasis:     p.CostBasis,
+			Price:         p.Price,
+			Value:         p.Value,
+			RealizedPnL:   p.RealizedPnL,
+			UnrealizedPnL: p.UnrealizedPnL,
+		}
+		if !p.PriceAt.IsZero() {
+			pos.PriceAt = p.PriceAt.Unix()
+		}
+		resp.Positions = append(resp.Positions, pos)
+	}
+	return resp
+}
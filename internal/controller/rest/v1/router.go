@@ -3,9 +3,13 @@ package v1
 
 import (
 	"log/slog"
-	"net/http"
+	"sync/atomic"
 
 	_ "github.com/Homyakadze14/AFFARM_tz/docs"
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/config"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	v2 "github.com/Homyakadze14/AFFARM_tz/internal/controller/rest/v2"
 	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
 
 	"github.com/gin-contrib/cors"
@@ -15,29 +19,80 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// Router holds settings of routes which are reloaded without restart.
+type Router struct {
+	cors        atomic.Pointer[gin.HandlerFunc]
+	export      atomic.Pointer[config.ExportConfig]
+	imp         atomic.Pointer[config.ImportConfig]
+	admin       atomic.Pointer[config.AdminConfig]
+	floatPrices atomic.Bool
+}
+
+// Reload applies CORS origins, export and import limits, the admin token and
+// the rendering of prices of cfg to subsequent requests.
+func (r *Router) Reload(cfg *config.Config) {
+	corsConf := cors.DefaultConfig()
+	corsConf.AllowOrigins = cfg.HTTP.CORSOrigins
+	corsConf.AllowCredentials = true
+	corsConf.ExposeHeaders = []string{rest.RequestIDHeader}
+	corsHandler := cors.New(corsConf)
+	r.cors.Store(&corsHandler)
+
+	export, imp, admin := cfg.Export, cfg.Import, cfg.Admin
+	r.export.Store(&export)
+	r.imp.Store(&imp)
+	r.admin.Store(&admin)
+	r.floatPrices.Store(cfg.HTTP.FloatPrices)
+}
+
 // Swagger spec:
 // @title       AFFARM
 // @description RestAPI for AFFARM
-// @version     1.0
+// @version     2.0
 // @host        localhost:8080
-// @BasePath    /api/v1
-func NewRouter(log *slog.Logger, handler *gin.Engine, h *usecase.CryptocurrencyService) {
+// @BasePath    /api
+//
+// @securityDefinitions.apikey AdminToken
+// @in          header
+// @name        Authorization
+// @description Admin token as "Bearer <ADMIN_TOKEN>"
+func NewRouter(
+	log *slog.Logger,
+	handler *gin.Engine,
+	cfg *config.Config,
+	h *usecase.CryptocurrencyService,
+	ps *usecase.PortfolioService,
+	is *usecase.ImportService,
+	pss *usecase.ParserService,
+	hs *usecase.HealthService,
+	leader Leader,
+) *Router {
+	router := &Router{}
+	router.Reload(cfg)
+
 	// Options
-	handler.Use(gin.Logger())
-	handler.Use(gin.Recovery())
+	rest.UseJSONFieldNames()
+	handler.Use(metricsMiddleware())
+	handler.Use(tracingMiddleware())
+	handler.Use(loggingMiddleware(log, &router.export))
+	handler.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		rest.WriteProblem(c, common.ErrUnexpected)
+	}))
+	handler.NoRoute(func(c *gin.Context) {
+		rest.WriteProblem(c, common.ErrRouteNotFound)
+	})
 
 	// Set cors
-	corsConf := cors.DefaultConfig()
-	corsConf.AllowOrigins = []string{"http://localhost:5173", "http://147.45.235.14:5173"}
-	corsConf.AllowCredentials = true
-	handler.Use(cors.New(corsConf))
+	handler.Use(func(c *gin.Context) {
+		(*router.cors.Load())(c)
+	})
 
 	// Swagger
 	swaggerHandler := ginSwagger.DisablingWrapHandler(swaggerFiles.Handler, "DISABLE_SWAGGER_HTTP_HANDLER")
 	handler.GET("/swagger/*any", swaggerHandler)
 
 	// K8s probe
-	handler.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+	NewHealthRoutes(handler, leader, hs)
 
 	// Prometheus metrics
 	handler.GET("/metrics", gin.WrapH(promhttp.Handler()))
@@ -45,6 +100,21 @@ func NewRouter(log *slog.Logger, handler *gin.Engine, h *usecase.CryptocurrencyS
 	// Routers
 	g := handler.Group("/api/v1")
 	{
-		NewHellotRoutes(log, g, h)
+		NewHellotRoutes(log, g, h, &router.floatPrices)
+		NewConvertRoutes(log, g, h, &router.floatPrices)
+		NewCorrelationRoutes(log, g, h)
+		NewPortfolioRoutes(log, g, ps)
+		NewExportRoutes(log, g, h, &router.export)
+		admin := g.Group("", adminAuth(&router.admin))
+		NewImportRoutes(log, admin, is, &router.imp)
+		NewParserRoutes(log, admin, pss)
 	}
+
+	// Resource oriented routes, v1 is kept for compatibility
+	g2 := handler.Group("/api/v2")
+	{
+		v2.NewCurrencyRoutes(log, g2, h)
+	}
+
+	return router
 }
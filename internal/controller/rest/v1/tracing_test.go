@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	psg "github.com/Homyakadze14/AFFARM_tz/internal/infra/postgres"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// servePostgres accepts connections on l and answers every simple query
+// with an empty result.
+func servePostgres(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+
+			backend := pgproto3.NewBackend(conn, conn)
+			if _, err := backend.ReceiveStartupMessage(); err != nil {
+				return
+			}
+			backend.Send(&pgproto3.AuthenticationOk{})
+			backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+			backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+			backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+
+			for {
+				msg, err := backend.Receive()
+				if err != nil {
+					return
+				}
+				switch msg.(type) {
+				case *pgproto3.Query:
+					backend.Send(&pgproto3.RowDescription{})
+					backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")})
+					backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+					if err := backend.Flush(); err != nil {
+						return
+					}
+				case *pgproto3.Terminate:
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestTracingSpanChain(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp, err := tracing.New(exporter, tracing.SyncExport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.Shutdown(context.Background())
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go servePostgres(l)
+
+	pg, err := postgres.New("postgres://user@"+l.Addr().String()+"/db?sslmode=disable&default_query_exec_mode=simple_protocol",
+		postgres.ConnAttempts(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pg.Close()
+
+	// Queries outside of traced requests do not start traces.
+	if _, err := psg.NewCryptocurrencyRepository(pg).GetBySymbol(context.Background(), "BTC"); err == nil {
+		t.Fatal("expected no cryptocurrency")
+	}
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Fatalf("got %d spans of untraced query, want none", len(spans))
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := usecase.NewCryptocurrencyService(log, psg.NewCryptocurrencyRepository(pg), nil, nil, nil, nil, nil, nil, "USDT")
+	r := &cryptocurrencyRoutes{log, h, new(atomic.Bool)}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(tracingMiddleware())
+	engine.GET("/v1/currency/:symbol/stats", r.stats)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/currency/BTC/stats?from=1&to=2", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+
+	server, ok := byName["GET /v1/currency/:symbol/stats"]
+	if !ok || server.SpanKind != trace.SpanKindServer {
+		t.Fatalf("no server span in %v", names(spans))
+	}
+	service, ok := byName["CryptocurrencyService.Stats"]
+	if !ok {
+		t.Fatalf("no service span in %v", names(spans))
+	}
+	query, ok := byName["SELECT"]
+	if !ok || query.SpanKind != trace.SpanKindClient {
+		t.Fatalf("no query span in %v", names(spans))
+	}
+
+	if service.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("service span is not a child of the server span")
+	}
+	if query.Parent.SpanID() != service.SpanContext.SpanID() {
+		t.Errorf("query span is not a child of the service span")
+	}
+	if query.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Errorf("query span is not in the trace of the request")
+	}
+}
+
+func names(spans tracetest.SpanStubs) []string {
+	out := make([]string, 0, len(spans))
+	for _, s := range spans {
+		out = append(out, s.Name)
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package v2
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+)
+
+// historyCacheControl lets clients and proxies reuse historical prices for an
+// hour. Later they revalidate them with the ETag, as imports may backfill
+// samples nearer to the requested time.
+const historyCacheControl = "public, max-age=3600"
+
+// settled reports whether the sample at sampled is the nearest price to at
+// for good: samples polled from now on are farther from at than it.
+func settled(sampled, at, now time.Time) bool {
+	d := sampled.Sub(at)
+	if d < 0 {
+		d = -d
+	}
+	return d < now.Sub(at)
+}
+
+// priceETag returns a strong ETag of the price of symbol.
+func priceETag(symbol string, p *dto.PriceResponse) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%d|%s", symbol, p.Currency, p.Timestamp, p.Price.Value.String())
+	return fmt.Sprintf(`"%x"`, h.Sum64())
+}
+
+// matchETag reports whether the If-None-Match header matches etag, comparing
+// weakly as RFC 9110 requires for GET.
+func matchETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
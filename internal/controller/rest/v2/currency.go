@@ -0,0 +1,192 @@
+// Package v2 implements resource oriented routing paths. Each services in own file.
+package v2
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/controller/rest"
+	"github.com/Homyakadze14/AFFARM_tz/internal/dto"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/internal/usecase"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type currencyRoutes struct {
+	log *slog.Logger
+	h   *usecase.CryptocurrencyService
+}
+
+func NewCurrencyRoutes(log *slog.Logger, handler *gin.RouterGroup, h *usecase.CryptocurrencyService) {
+	r := &currencyRoutes{log, h}
+
+	g := handler.Group("currencies")
+	{
+		g.PUT("/:symbol", r.put)
+		g.DELETE("/:symbol", r.delete)
+		g.GET("/:symbol/price", r.price)
+	}
+}
+
+// @Summary     Track cryptocurrency
+// @Description Start tracking cryptocurrency or update its polling interval and priority
+// @ID          PutCurrency
+// @Tags  	    Currencies
+// @Accept      json
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Param 		currency body dto.PutCurrencyRequest false "Tracking schedule"
+// @Produce     json
+// @Success     200 {object} dto.CurrencyResponse "Schedule of tracked cryptocurrency updated"
+// @Success     201 {object} dto.CurrencyResponse "Cryptocurrency tracked"
+// @Header      201 {string} Location "URL of the cryptocurrency"
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Failure     502 {object} dto.Problem
+// @Failure     503 {object} dto.Problem
+// @Router      /v2/currencies/{symbol} [put]
+func (r *currencyRoutes) put(c *gin.Context) {
+	const op = "currencyRoutes.put"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	// The body is optional, coins without schedule get the defaults.
+	var req dto.PutCurrencyRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			rest.HandleErr(c, log, err)
+			return
+		}
+	}
+
+	var interval time.Duration
+	if req.Interval != "" {
+		var err error
+		interval, err = time.ParseDuration(req.Interval)
+		if err != nil || interval < time.Second {
+			rest.HandleErr(c, log, common.InvalidField("interval", "duration", "must be a duration of at least 1s"))
+			return
+		}
+	}
+
+	coin := &entity.TrackedCoin{
+		Cryptocurrency: entity.Cryptocurrency{Symbol: c.Param("symbol")},
+		PollInterval:   interval,
+		Priority:       req.Priority,
+	}
+	created, err := r.h.Add(c.Request.Context(), coin, usecase.KeepSchedule{})
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.CurrencyResponse{
+		Symbol:   coin.Symbol,
+		Priority: coin.Priority,
+	}
+	if coin.PollInterval > 0 {
+		resp.Interval = coin.PollInterval.String()
+	}
+	if !created {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	c.Header("Location", c.Request.URL.Path)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// @Summary     Untrack cryptocurrency
+// @Description Stop tracking cryptocurrency, its price history is kept
+// @ID          DeleteCurrency
+// @Tags  	    Currencies
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Success     204
+// @Failure     404 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v2/currencies/{symbol} [delete]
+func (r *currencyRoutes) delete(c *gin.Context) {
+	const op = "currencyRoutes.delete"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	err := r.h.Remove(c.Request.Context(), &entity.Cryptocurrency{Symbol: c.Param("symbol")})
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary     Get price
+// @Description Get the price nearest to the time, the latest price without it. Historical prices are
+// @Description cacheable and revalidated with ETag.
+// @ID          GetCurrencyPrice
+// @Tags  	    Currencies
+// @Param 		symbol path string true "Cryptocurrency symbol" example(BTC)
+// @Param 		price query dto.CurrencyPriceRequest false "Price time and currency"
+// @Param 		If-None-Match header string false "ETag of a cached price"
+// @Produce     json
+// @Success     200 {object} dto.PriceResponse
+// @Header      200 {string} ETag "Version of historical price"
+// @Header      200 {string} Cache-Control "Caching policy of the price"
+// @Success     304 "Cached historical price is up to date"
+// @Failure     400 {object} dto.Problem
+// @Failure     404 {object} dto.Problem
+// @Failure     422 {object} dto.Problem
+// @Failure     500 {object} dto.Problem
+// @Router      /v2/currencies/{symbol}/price [get]
+func (r *currencyRoutes) price(c *gin.Context) {
+	const op = "currencyRoutes.price"
+	log := logctx.From(c.Request.Context(), r.log).With(
+		slog.String("op", op),
+	)
+
+	var req dto.CurrencyPriceRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	now := time.Now()
+	at := now
+	if req.At != 0 {
+		at = time.Unix(req.At, 0)
+	}
+
+	currency := rest.Currency(req.Currency, r.h.Quote())
+	symbol := c.Param("symbol")
+	hist, err := r.h.Price(c.Request.Context(), symbol, at, currency)
+	if err != nil {
+		rest.HandleErr(c, log, err)
+		return
+	}
+
+	resp := &dto.PriceResponse{
+		Price:     dto.NewPrice(hist.Price, false),
+		Currency:  currency,
+		Timestamp: hist.Timestamp.Unix(),
+	}
+
+	if req.At == 0 || !settled(hist.Timestamp, at, now) {
+		c.Header("Cache-Control", "no-cache")
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	etag := priceETag(symbol, resp)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", historyCacheControl)
+	if matchETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,27 @@
+package dto
+
+type ConvertRequest struct {
+	From      string `form:"from" binding:"required" example:"SOL"`
+	To        string `form:"to" binding:"required" example:"ETH"`
+	Amount    string `form:"amount" binding:"required" example:"1.5"`
+	Timestamp int64  `form:"timestamp" binding:"required" example:"1754578944"`
+	Tolerance int64  `form:"tolerance" binding:"omitempty,gt=0" example:"300"`
+}
+
+type ConvertLeg struct {
+	Symbol      string `json:"symbol"`
+	Price       Price  `json:"price" swaggertype:"string"`
+	Timestamp   int64  `json:"timestamp"`
+	SkewSeconds int64  `json:"skew_seconds"`
+}
+
+type ConvertResponse struct {
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	Quote     string       `json:"quote"`
+	Amount    Price        `json:"amount" swaggertype:"string"`
+	Rate      Price        `json:"rate" swaggertype:"string"`
+	Result    Price        `json:"result" swaggertype:"string"`
+	Timestamp int64        `json:"timestamp"`
+	Legs      []ConvertLeg `json:"legs"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+type CorrelationRequest struct {
+	Symbols  string `form:"symbols" binding:"required" example:"BTC,ETH,SOL"`
+	Method   string `form:"method" binding:"omitempty,oneof=pearson spearman" example:"pearson"`
+	Interval string `form:"interval" binding:"required" example:"1h"`
+	From     int64  `form:"from" binding:"required" example:"1754578944"`
+	To       int64  `form:"to" binding:"required" example:"1754665344"`
+}
+
+type CorrelationResponse struct {
+	Symbols  []string     `json:"symbols"`
+	Method   string       `json:"method"`
+	Interval string       `json:"interval"`
+	Points   int          `json:"points"`
+	Matrix   [][]*float64 `json:"matrix"`
+}
@@ -1,7 +1,15 @@
 package dto
 
+import "github.com/shopspring/decimal"
+
 type AddCryptocurrencyRequest struct {
 	Symbol string `json:"symbol" binding:"required" example:"BTC"`
+	// Interval is the polling interval, PARSER_INTERVAL by default. Omitted
+	// interval is kept for an already tracked coin, empty one resets it.
+	Interval *string `json:"interval" example:"10s"`
+	// Priority orders coins due at the same time, higher first. Omitted
+	// priority is kept for an already tracked coin, 0 by default.
+	Priority *int `json:"priority" binding:"omitempty,gte=0,lte=100" example:"10"`
 }
 
 type RemoveCryptocurrencyRequest struct {
@@ -11,9 +19,71 @@ type RemoveCryptocurrencyRequest struct {
 type PriceRequest struct {
 	Symbol    string `json:"symbol" binding:"required" example:"BTC"`
 	Timestamp int64  `json:"timestamp" binding:"required" example:"1754578944"`
+	Currency  string `json:"currency" example:"EUR"`
 }
 
 type PriceResponse struct {
-	Price     float64 `json:"price"`
-	Timestamp int64   `json:"timestamp"`
+	Price     Price  `json:"price" swaggertype:"string" example:"116894.01000000"`
+	Currency  string `json:"currency"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type StatsRequest struct {
+	From int64 `form:"from" binding:"required" example:"1754578944"`
+	To   int64 `form:"to" binding:"required" example:"1754665344"`
+	// Currency of the prices, the quote currency by default.
+	Currency string `form:"currency" example:"EUR"`
+}
+
+type StatsResponse struct {
+	Symbol        string          `json:"symbol"`
+	Currency      string          `json:"currency"`
+	Count         int64           `json:"count"`
+	First         decimal.Decimal `json:"first" swaggertype:"string"`
+	FirstAt       int64           `json:"first_at"`
+	Last          decimal.Decimal `json:"last" swaggertype:"string"`
+	LastAt        int64           `json:"last_at"`
+	Min           decimal.Decimal `json:"min" swaggertype:"string"`
+	MinAt         int64           `json:"min_at"`
+	Max           decimal.Decimal `json:"max" swaggertype:"string"`
+	MaxAt         int64           `json:"max_at"`
+	Avg           decimal.Decimal `json:"avg" swaggertype:"string"`
+	StdDev        decimal.Decimal `json:"stddev" swaggertype:"string"`
+	Change        decimal.Decimal `json:"change" swaggertype:"string"`
+	ChangePercent decimal.Decimal `json:"change_percent" swaggertype:"string"`
+}
+
+type ExportRequest struct {
+	Symbols string `form:"symbols" binding:"required" example:"BTC,ETH"`
+	Format  string `form:"format" binding:"omitempty,oneof=csv ndjson" example:"csv"`
+	From    int64  `form:"from" binding:"required" example:"1754578944"`
+	To      int64  `form:"to" binding:"required" example:"1754665344"`
+	// Currency of the prices, the quote currency by default.
+	Currency string `form:"currency" example:"EUR"`
+}
+
+type ExportRow struct {
+	Symbol    string          `json:"symbol"`
+	Timestamp int64           `json:"timestamp"`
+	Price     decimal.Decimal `json:"price"`
+}
+
+type PutCurrencyRequest struct {
+	// Interval is the polling interval, PARSER_INTERVAL by default.
+	Interval string `json:"interval" example:"10s"`
+	// Priority orders coins due at the same time, higher first.
+	Priority int `json:"priority" binding:"gte=0,lte=100" example:"10"`
+}
+
+type CurrencyResponse struct {
+	Symbol string `json:"symbol"`
+	// Interval is the own polling interval, omitted for PARSER_INTERVAL.
+	Interval string `json:"interval,omitempty"`
+	Priority int    `json:"priority"`
+}
+
+type CurrencyPriceRequest struct {
+	// At is the unix time of the price, the latest price by default.
+	At       int64  `form:"at" binding:"gte=0" example:"1754578944"`
+	Currency string `form:"currency" example:"EUR"`
 }
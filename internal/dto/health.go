@@ -0,0 +1,18 @@
+package dto
+
+// ReadinessResponse reports the status of the replica, ready, degraded or
+// not_ready, and of each of its dependencies by name, up, degraded or down.
+type ReadinessResponse struct {
+	Status     string                       `json:"status" example:"ready"`
+	Components map[string]ComponentResponse `json:"components"`
+}
+
+type ComponentResponse struct {
+	Status string `json:"status" example:"up"`
+	// State is the circuit breaker state of upstreams.
+	State string `json:"state,omitempty" example:"closed"`
+	// Heartbeat is the unix time the background loop last ran.
+	Heartbeat int64  `json:"heartbeat,omitempty" example:"1754578934"`
+	LatencyMS int64  `json:"latency_ms,omitempty" example:"2"`
+	Error     string `json:"error,omitempty"`
+}
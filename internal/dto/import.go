@@ -0,0 +1,20 @@
+package dto
+
+type ImportRequest struct {
+	Format string `form:"format" binding:"required,oneof=csv ndjson" example:"csv"`
+}
+
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportResponse struct {
+	Rows     int64            `json:"rows"`
+	Inserted int64            `json:"inserted"`
+	Updated  int64            `json:"updated"`
+	Skipped  int64            `json:"skipped"`
+	Failed   int64            `json:"failed"`
+	Created  []string         `json:"created"`
+	Errors   []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+type IndicatorRequest struct {
+	Name     string `form:"name" binding:"required,oneof=sma ema rsi bollinger" example:"rsi"`
+	Period   int    `form:"period" binding:"required,gt=0" example:"14"`
+	Interval string `form:"interval" binding:"required" example:"1h"`
+	From     int64  `form:"from" binding:"required" example:"1754578944"`
+	To       int64  `form:"to" binding:"required" example:"1754665344"`
+	// Currency of the prices, the quote currency by default.
+	Currency string `form:"currency" example:"EUR"`
+}
+
+type IndicatorPoint struct {
+	Timestamp int64    `json:"timestamp"`
+	Value     *float64 `json:"value"`
+	Upper     *float64 `json:"upper,omitempty"`
+	Lower     *float64 `json:"lower,omitempty"`
+}
+
+type IndicatorResponse struct {
+	Symbol   string           `json:"symbol"`
+	Currency string           `json:"currency"`
+	Name     string           `json:"name"`
+	Period   int              `json:"period"`
+	Interval string           `json:"interval"`
+	Points   []IndicatorPoint `json:"points"`
+}
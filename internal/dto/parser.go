@@ -0,0 +1,30 @@
+package dto
+
+type ParserStatusResponse struct {
+	// Leader is false on replicas that do not poll prices.
+	Leader        bool                 `json:"leader"`
+	Paused        bool                 `json:"paused"`
+	Workers       int                  `json:"workers"`
+	QueueDepth    int                  `json:"queue_depth"`
+	QueueCapacity int                  `json:"queue_capacity"`
+	Skipped       int64                `json:"skipped"`
+	Coins         []CoinStatusResponse `json:"coins"`
+}
+
+// CoinStatusResponse has unix timestamps which are omitted until the event happens.
+type CoinStatusResponse struct {
+	Symbol      string `json:"symbol" example:"BTC"`
+	Interval    string `json:"interval" example:"10s"`
+	Priority    int    `json:"priority" example:"10"`
+	Paused      bool   `json:"paused"`
+	NextPoll    int64  `json:"next_poll" example:"1754578944"`
+	LastSuccess int64  `json:"last_success,omitempty" example:"1754578934"`
+	LastError   string `json:"last_error,omitempty"`
+	LastErrorAt int64  `json:"last_error_at,omitempty"`
+	Errors      int64  `json:"errors"`
+	LatencyMS   int64  `json:"latency_ms" example:"120"`
+}
+
+type ResizeParserRequest struct {
+	Workers int `json:"workers" binding:"required,gte=1,lte=256" example:"10"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+type CreatePortfolioRequest struct {
+	Name       string `json:"name" binding:"required,max=100" example:"main"`
+	Accounting string `json:"accounting" binding:"omitempty,oneof=fifo average" example:"fifo"`
+}
+
+type PortfolioResponse struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Accounting string `json:"accounting"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type AddTradeRequest struct {
+	Symbol    string `json:"symbol" binding:"required" example:"BTC"`
+	Side      string `json:"side" binding:"required,oneof=buy sell" example:"buy"`
+	Quantity  string `json:"quantity" binding:"required" example:"0.5"`
+	Price     string `json:"price" binding:"required" example:"116894.01"`
+	Timestamp int64  `json:"timestamp" binding:"required" example:"1754578944"`
+}
+
+type TradeResponse struct {
+	ID        int             `json:"id"`
+	Symbol    string          `json:"symbol"`
+	Side      string          `json:"side"`
+	Quantity  decimal.Decimal `json:"quantity" swaggertype:"string"`
+	Price     decimal.Decimal `json:"price" swaggertype:"string"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+type PortfolioValueRequest struct {
+	Timestamp int64 `form:"timestamp" example:"1754578944"`
+}
+
+type PortfolioHistoryRequest struct {
+	Interval string `form:"interval" binding:"required" example:"1h"`
+	From     int64  `form:"from" binding:"required" example:"1754578944"`
+	To       int64  `form:"to" binding:"required" example:"1754665344"`
+}
+
+type PositionResponse struct {
+	Symbol        string          `json:"symbol"`
+	Quantity      decimal.Decimal `json:"quantity" swaggertype:"string"`
+	CostBasis     decimal.Decimal `json:"cost_basis" swaggertype:"string"`
+	Price         decimal.Decimal `json:"price" swaggertype:"string"`
+	PriceAt       int64           `json:"price_at,omitempty"`
+	Value         decimal.Decimal `json:"value" swaggertype:"string"`
+	RealizedPnL   decimal.Decimal `json:"realized_pnl" swaggertype:"string"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl" swaggertype:"string"`
+}
+
+type PortfolioValueResponse struct {
+	PortfolioID   int                `json:"portfolio_id"`
+	Timestamp     int64              `json:"timestamp"`
+	Value         decimal.Decimal    `json:"value" swaggertype:"string"`
+	CostBasis     decimal.Decimal    `json:"cost_basis" swaggertype:"string"`
+	RealizedPnL   decimal.Decimal    `json:"realized_pnl" swaggertype:"string"`
+	UnrealizedPnL decimal.Decimal    `json:"unrealized_pnl" swaggertype:"string"`
+	Positions     []PositionResponse `json:"positions,omitempty"`
+}
+
+type PortfolioPnLResponse struct {
+	PortfolioID int             `json:"portfolio_id"`
+	Timestamp   int64           `json:"timestamp"`
+	Realized    decimal.Decimal `json:"realized" swaggertype:"string"`
+	Unrealized  decimal.Decimal `json:"unrealized" swaggertype:"string"`
+	Total       decimal.Decimal `json:"total" swaggertype:"string"`
+}
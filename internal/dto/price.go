@@ -0,0 +1,21 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// Price is a decimal rendered in JSON as a string, or as a number if Float is
+// set for clients which still expect the float response.
+type Price struct {
+	Value decimal.Decimal
+	Float bool
+}
+
+func NewPrice(value decimal.Decimal, float bool) Price {
+	return Price{Value: value, Float: float}
+}
+
+func (p Price) MarshalJSON() ([]byte, error) {
+	if p.Float {
+		return []byte(p.Value.String()), nil
+	}
+	return p.Value.MarshalJSON()
+}
@@ -0,0 +1,22 @@
+package dto
+
+// Problem is an RFC 7807 problem details response served as
+// application/problem+json. Code is stable and meant for clients to branch
+// on, Detail is human readable and may change.
+type Problem struct {
+	Type      string         `json:"type" example:"urn:affarm:error:validation_failed"`
+	Title     string         `json:"title" example:"Unprocessable Entity"`
+	Status    int            `json:"status" example:"422"`
+	Detail    string         `json:"detail" example:"request validation failed"`
+	Instance  string         `json:"instance" example:"/api/v1/currency/price"`
+	Code      string         `json:"code" example:"validation_failed"`
+	RequestID string         `json:"request_id,omitempty" example:"6a2fb3f5-5947-4fce-937c-25b5b6ccf637"`
+	Errors    []FieldProblem `json:"errors,omitempty"`
+}
+
+// FieldProblem is an invalid request field. Code is the failed rule.
+type FieldProblem struct {
+	Field   string `json:"field" example:"symbol"`
+	Code    string `json:"code" example:"required"`
+	Message string `json:"message" example:"must be provided"`
+}
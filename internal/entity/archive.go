@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// Archive describes price history of a cryptocurrency over
+// [PeriodStart, PeriodEnd) moved from the database to object storage.
+type Archive struct {
+	ID               int
+	CryptocurrencyID int
+	PeriodStart      time.Time
+	PeriodEnd        time.Time
+	Key              string
+	Rows             int64
+	CreatedAt        time.Time
+}
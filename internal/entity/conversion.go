@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ConversionLeg struct {
+	Symbol string
+	Sample *PriceHistory
+	Skew   time.Duration
+}
+
+type Conversion struct {
+	From      string
+	To        string
+	Quote     string
+	Amount    decimal.Decimal
+	Rate      decimal.Decimal
+	Result    decimal.Decimal
+	Timestamp time.Time
+	Legs      []ConversionLeg
+}
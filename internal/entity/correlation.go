@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+type CorrelationMatrix struct {
+	Symbols  []string
+	Method   string
+	Interval time.Duration
+	// Points is the number of aligned returns used for every coefficient.
+	Points int
+	Matrix [][]float64
+}
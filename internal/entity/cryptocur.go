@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type Cryptocurrency struct {
 	ID     int
@@ -11,11 +15,48 @@ type Tracking struct {
 	ID               int
 	CryptocurrencyID int
 	IsActive         bool
+	// PollInterval is how often the parser polls the price, zero for the
+	// default interval; higher Priority coins are polled first when several
+	// are due.
+	PollInterval time.Duration
+	Priority     int
+}
+
+// TrackedCoin is an actively tracked cryptocurrency with its polling schedule.
+type TrackedCoin struct {
+	Cryptocurrency
+	PollInterval time.Duration
+	Priority     int
 }
 
 type PriceHistory struct {
 	ID               int
 	CryptocurrencyID int
-	Price            float64
+	Price            decimal.Decimal
 	Timestamp        time.Time
 }
+
+type PriceStats struct {
+	Count         int64
+	First         decimal.Decimal
+	FirstAt       time.Time
+	Last          decimal.Decimal
+	LastAt        time.Time
+	Min           decimal.Decimal
+	MinAt         time.Time
+	Max           decimal.Decimal
+	MaxAt         time.Time
+	Avg           decimal.Decimal
+	StdDev        decimal.Decimal
+	Change        decimal.Decimal
+	ChangePercent decimal.Decimal
+}
+
+type Candle struct {
+	Timestamp time.Time
+	Open      decimal.Decimal
+	High      decimal.Decimal
+	Low       decimal.Decimal
+	Close     decimal.Decimal
+	Count     int64
+}
@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FXRate is the amount of Currency paid for one unit of the quote currency on Date.
+type FXRate struct {
+	Currency string
+	Date     time.Time
+	Rate     decimal.Decimal
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// States of a circuit breaker of an upstream.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// Readiness is the outcome of dependency checks of this replica. It is
+// ready when every component is healthy, and degraded when some of them
+// work with reduced capability.
+type Readiness struct {
+	Ready      bool
+	Degraded   bool
+	Components []ComponentHealth
+}
+
+// ComponentHealth is the check of a dependency. State and Heartbeat are set
+// by checks they apply to. Degraded components are still healthy, as the
+// replica serves requests without them.
+type ComponentHealth struct {
+	Name      string
+	Healthy   bool
+	Degraded  bool
+	State     string
+	Heartbeat time.Time
+	Latency   time.Duration
+	Error     string
+}
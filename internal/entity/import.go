@@ -0,0 +1,17 @@
+package entity
+
+type ImportRowError struct {
+	Line  int
+	Error string
+}
+
+type ImportReport struct {
+	Rows     int64
+	Inserted int64
+	Updated  int64
+	Skipped  int64
+	Failed   int64
+	// Created lists symbols of cryptocurrencies created on demand.
+	Created []string
+	Errors  []ImportRowError
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// IndicatorPoint holds indicator values aligned with a candle.
+// Values not available yet because of the warm-up period are NaN.
+type IndicatorPoint struct {
+	Timestamp time.Time
+	Value     float64
+	Upper     float64
+	Lower     float64
+}
+
+type IndicatorSeries struct {
+	Name     string
+	Period   int
+	Interval time.Duration
+	Points   []IndicatorPoint
+}
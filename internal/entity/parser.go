@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// ParserStatus is a snapshot of the price parser of this replica.
+type ParserStatus struct {
+	Leader        bool
+	Paused        bool
+	Workers       int
+	QueueDepth    int
+	QueueCapacity int
+	Skipped       int64
+	Coins         []CoinStatus
+}
+
+// CoinStatus is the schedule of a tracked coin and the outcome of its polls.
+// Zero times mean the event did not happen since the coin was scheduled.
+type CoinStatus struct {
+	TrackedCoin
+	Paused      bool
+	Added       time.Time
+	NextPoll    time.Time
+	LastSuccess time.Time
+	LastError   string
+	LastErrorAt time.Time
+	Errors      int64
+	Latency     time.Duration
+}
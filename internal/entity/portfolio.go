@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	AccountingFIFO    = "fifo"
+	AccountingAverage = "average"
+)
+
+type Portfolio struct {
+	ID         int
+	Name       string
+	Accounting string
+	CreatedAt  time.Time
+}
+
+// Trade is a buy (positive Quantity) or sell (negative Quantity) at Price in the quote currency.
+type Trade struct {
+	ID               int
+	PortfolioID      int
+	CryptocurrencyID int
+	Symbol           string
+	Quantity         decimal.Decimal
+	Price            decimal.Decimal
+	Timestamp        time.Time
+}
+
+type Position struct {
+	Symbol        string
+	Quantity      decimal.Decimal
+	CostBasis     decimal.Decimal
+	Price         decimal.Decimal
+	PriceAt       time.Time
+	Value         decimal.Decimal
+	RealizedPnL   decimal.Decimal
+	UnrealizedPnL decimal.Decimal
+}
+
+type PortfolioValuation struct {
+	PortfolioID   int
+	Timestamp     time.Time
+	Value         decimal.Decimal
+	CostBasis     decimal.Decimal
+	RealizedPnL   decimal.Decimal
+	UnrealizedPnL decimal.Decimal
+	Positions     []Position
+}
@@ -0,0 +1,93 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+	"github.com/shopspring/decimal"
+)
+
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// row is the Parquet schema of archived samples. Prices are kept as decimal
+// strings since NUMERIC(20, 8) does not fit a 64-bit unscaled value.
+type row struct {
+	ID               int64     `parquet:"id"`
+	CryptocurrencyID int32     `parquet:"cryptocurrency_id"`
+	Timestamp        time.Time `parquet:"timestamp,timestamp(microsecond)"`
+	Price            string    `parquet:"price"`
+}
+
+// ParquetArchive encodes price history as zstd-compressed Parquet objects.
+type ParquetArchive struct {
+	store Store
+}
+
+func NewParquetArchive(store Store) *ParquetArchive {
+	return &ParquetArchive{store: store}
+}
+
+func (a *ParquetArchive) Write(ctx context.Context, key string, histories []entity.PriceHistory) error {
+	const op = "ParquetArchive.Write"
+
+	rows := make([]row, 0, len(histories))
+	for _, h := range histories {
+		rows = append(rows, row{
+			ID:               int64(h.ID),
+			CryptocurrencyID: int32(h.CryptocurrencyID),
+			Timestamp:        h.Timestamp.UTC(),
+			Price:            h.Price.String(),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := parquet.Write(&buf, rows, parquet.Compression(&zstd.Codec{})); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.store.Put(ctx, key, buf.Bytes()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Read returns samples of the object in the order they were written.
+func (a *ParquetArchive) Read(ctx context.Context, key string) ([]entity.PriceHistory, error) {
+	const op = "ParquetArchive.Read"
+
+	data, err := a.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := parquet.Read[row](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	histories := make([]entity.PriceHistory, 0, len(rows))
+	for _, r := range rows {
+		price, err := decimal.NewFromString(r.Price)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		histories = append(histories, entity.PriceHistory{
+			ID:               int(r.ID),
+			CryptocurrencyID: int(r.CryptocurrencyID),
+			Price:            price,
+			Timestamp:        r.Timestamp,
+		})
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,94 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type HistoryArchiver interface {
+	Archive(ctx context.Context) (int, error)
+}
+
+// Archiver periodically moves aged price history to object storage. Only the
+// leader archives, right after becoming leader and then every interval, so
+// replicas do not write the same archives.
+type Archiver struct {
+	log      *slog.Logger
+	interval time.Duration
+	archiver HistoryArchiver
+	leader   Leader
+	cancel   context.CancelFunc
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewArchiver(
+	log *slog.Logger,
+	interval time.Duration,
+	archiver HistoryArchiver,
+	leader Leader,
+) *Archiver {
+	return &Archiver{
+		log:      log,
+		interval: interval,
+		archiver: archiver,
+		leader:   leader,
+		done:     make(chan struct{}),
+	}
+}
+
+func (a *Archiver) Start() {
+	a.log.Info("Start price history archiving")
+
+	// Stop interrupts a running pass; unfinished months are retried.
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		ticker := time.NewTicker(min(a.interval, leaderCheckInterval))
+		defer ticker.Stop()
+
+		var last time.Time
+		for {
+			switch {
+			case !a.leader.IsLeader():
+				last = time.Time{}
+			case time.Since(last) >= a.interval:
+				a.archive(ctx)
+				last = time.Now()
+			}
+
+			select {
+			case <-ticker.C:
+			case <-a.done:
+				return
+			}
+		}
+	}()
+}
+
+func (a *Archiver) archive(ctx context.Context) {
+	const op = "Archiver.archive"
+	log := a.log.With(slog.String("op", op))
+
+	ctx, done := context.WithTimeout(ctx, a.interval)
+	defer done()
+
+	_, err := a.archiver.Archive(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("Error archiving price history: %v", err))
+	}
+}
+
+func (a *Archiver) Stop() {
+	a.log.Info("Stop price history archiving")
+	a.cancel()
+	close(a.done)
+	a.wg.Wait()
+}
@@ -0,0 +1,76 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type FXSyncer interface {
+	Sync(ctx context.Context) (int, error)
+}
+
+// FXUpdater periodically imports fiat rates.
+type FXUpdater struct {
+	log            *slog.Logger
+	updateInterval time.Duration
+	syncer         FXSyncer
+	done           chan struct{}
+	wg             sync.WaitGroup
+}
+
+func NewFXUpdater(
+	log *slog.Logger,
+	updateInterval time.Duration,
+	syncer FXSyncer,
+) *FXUpdater {
+	return &FXUpdater{
+		log:            log,
+		updateInterval: updateInterval,
+		syncer:         syncer,
+		done:           make(chan struct{}),
+	}
+}
+
+func (u *FXUpdater) Start() {
+	u.log.Info("Start fx rates updating")
+
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+
+		ticker := time.NewTicker(u.updateInterval)
+		defer ticker.Stop()
+
+		for {
+			u.sync()
+
+			select {
+			case <-ticker.C:
+			case <-u.done:
+				return
+			}
+		}
+	}()
+}
+
+func (u *FXUpdater) sync() {
+	const op = "FXUpdater.sync"
+	log := u.log.With(slog.String("op", op))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	_, err := u.syncer.Sync(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("Error syncing fx rates: %v", err))
+	}
+}
+
+func (u *FXUpdater) Stop() {
+	u.log.Info("Stop fx rates updating")
+	close(u.done)
+	u.wg.Wait()
+}
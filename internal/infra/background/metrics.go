@@ -0,0 +1,128 @@
+package background
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const metricsNamespace = "affarm"
+
+var (
+	fetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "parser",
+		Name:      "fetch_duration_seconds",
+		Help:      "Duration of price requests to the exchange.",
+		Buckets:   []float64{.025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"exchange", "symbol"})
+
+	fetchErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "parser",
+		Name:      "fetch_errors_total",
+		Help:      "Failed price requests to the exchange.",
+	}, []string{"exchange", "symbol"})
+
+	samplesWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "parser",
+		Name:      "samples_written_total",
+		Help:      "Price samples stored in the database.",
+	}, []string{"symbol"})
+
+	samplesSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "parser",
+		Name:      "samples_skipped_total",
+		Help:      "Price samples skipped as duplicates of stored ones.",
+	}, []string{"symbol"})
+
+	insertErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "parser",
+		Name:      "insert_errors_total",
+		Help:      "Price samples which failed to be stored.",
+	}, []string{"symbol"})
+
+	insertDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "parser",
+		Name:      "insert_duration_seconds",
+		Help:      "Duration of price sample inserts.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	})
+
+	droppedTicks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "parser",
+		Name:      "dropped_ticks_total",
+		Help:      "Scheduled polls skipped because the parser fell behind by more than an interval.",
+	}, []string{"symbol"})
+)
+
+var (
+	queueDepthDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "parser", "queue_depth"),
+		"Polls waiting in the task queue.", nil, nil)
+	queueCapacityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "parser", "queue_capacity"),
+		"Capacity of the task queue.", nil, nil)
+	workersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "parser", "workers"),
+		"Workers polling prices concurrently.", nil, nil)
+	leaderDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "parser", "leader"),
+		"Whether this replica polls prices.", nil, nil)
+	trackedCoinsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "parser", "tracked_coins"),
+		"Coins scheduled for polling.", nil, nil)
+	sinceLastSampleDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metricsNamespace, "parser", "seconds_since_last_sample"),
+		"Seconds since the last stored sample of the coin, or since it was scheduled. "+
+			"Reported by the leader for coins which are not paused.",
+		[]string{"symbol"}, nil)
+)
+
+// Describe implements prometheus.Collector.
+func (p *Parser) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+	ch <- queueCapacityDesc
+	ch <- workersDesc
+	ch <- leaderDesc
+	ch <- trackedCoinsDesc
+	ch <- sinceLastSampleDesc
+}
+
+// Collect implements prometheus.Collector with gauges computed at scrape time.
+func (p *Parser) Collect(ch chan<- prometheus.Metric) {
+	status := p.Status()
+	now := time.Now()
+
+	leader := 0.0
+	if status.Leader {
+		leader = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(status.QueueDepth))
+	ch <- prometheus.MustNewConstMetric(queueCapacityDesc, prometheus.GaugeValue, float64(status.QueueCapacity))
+	ch <- prometheus.MustNewConstMetric(workersDesc, prometheus.GaugeValue, float64(status.Workers))
+	ch <- prometheus.MustNewConstMetric(leaderDesc, prometheus.GaugeValue, leader)
+	ch <- prometheus.MustNewConstMetric(trackedCoinsDesc, prometheus.GaugeValue, float64(len(status.Coins)))
+
+	if !status.Leader || status.Paused {
+		return
+	}
+	for _, coin := range status.Coins {
+		if coin.Paused {
+			continue
+		}
+		since := coin.LastSuccess
+		if since.IsZero() {
+			since = coin.Added
+		}
+		ch <- prometheus.MustNewConstMetric(sinceLastSampleDesc, prometheus.GaugeValue,
+			now.Sub(since).Seconds(), coin.Symbol)
+	}
+}
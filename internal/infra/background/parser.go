@@ -1,56 +1,111 @@
 package background
 
 import (
+	"container/heap"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
 	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const taskChanSize = 50
+const (
+	taskChanSize = 50
+	// heartbeatInterval bounds the sleep of the dispatch loop, so its
+	// heartbeat stays fresh while no coin is due.
+	heartbeatInterval = 5 * time.Second
+)
+
+var tracer = otel.Tracer("github.com/Homyakadze14/AFFARM_tz/internal/infra/background")
+
+// task is a queued poll of the coin, traced as a child of the span which
+// queued it.
+type task struct {
+	coin entity.Cryptocurrency
+	span trace.SpanContext
+}
 
 type HistoryStorage interface {
 	Create(ctx context.Context, history *entity.PriceHistory) (*entity.PriceHistory, error)
 }
 
 type CurrencyStorage interface {
-	GetActive(ctx context.Context) ([]entity.Cryptocurrency, error)
+	GetActive(ctx context.Context) ([]entity.TrackedCoin, error)
+}
+
+// Leader reports whether this replica is the one ingesting prices.
+type Leader interface {
+	IsLeader() bool
 }
 
 type CryptoClient interface {
-	GetPrice(symbol string, currency string) (float64, error)
+	// Name identifies the exchange in metrics.
+	Name() string
+	GetPrice(ctx context.Context, symbol string, currency string) (decimal.Decimal, error)
 }
 
 type Parser struct {
-	coins          sync.Map
 	log            *slog.Logger
 	updateInterval time.Duration
 	maxWorkers     int
+	quote          string
 	hst            HistoryStorage
 	cst            CurrencyStorage
 	cryptoClient   CryptoClient
+	leader         Leader
+	skipped        atomic.Int64
+	heartbeat      atomic.Int64
+	mu             sync.Mutex
+	queue          schedule
+	coins          map[string]*scheduledCoin
+	version        uint64
+	changes        map[string]uint64
+	paused         bool
+	wake           chan struct{}
+	wmu            sync.Mutex
+	tasks          chan task
+	quits          []chan struct{}
+	stopped        bool
 	done           chan struct{}
 	wg             sync.WaitGroup
 }
 
+// NewParser creates the parser. Coins without own polling interval are
+// polled every updateInterval, prices are fetched in the quote currency.
 func NewParser(
 	log *slog.Logger,
 	updateInterval time.Duration,
 	maxWorkers int,
+	quote string,
 	hst HistoryStorage,
 	cst CurrencyStorage,
 	cryptoClient CryptoClient,
+	leader Leader,
 ) *Parser {
 	return &Parser{
 		log:            log,
 		updateInterval: updateInterval,
 		maxWorkers:     maxWorkers,
+		quote:          quote,
 		hst:            hst,
 		cst:            cst,
 		cryptoClient:   cryptoClient,
+		leader:         leader,
+		coins:          make(map[string]*scheduledCoin),
+		changes:        make(map[string]uint64),
+		wake:           make(chan struct{}, 1),
 	}
 }
 
@@ -62,11 +117,13 @@ func (p *Parser) Start() {
 		panic(err)
 	}
 
+	p.mu.Lock()
 	for _, cr := range crs {
-		p.coins.Store(cr.Symbol, cr)
+		p.schedule(cr, time.Now())
 	}
+	p.mu.Unlock()
 
-	taskChan := make(chan entity.Cryptocurrency, taskChanSize)
+	taskChan := make(chan task, taskChanSize)
 	p.done = make(chan struct{})
 
 	p.log.Info(fmt.Sprintf("Start parsing. Find %v coins", len(crs)))
@@ -74,20 +131,24 @@ func (p *Parser) Start() {
 	p.initWorkers(taskChan)
 
 	go func() {
-		ticker := time.NewTicker(p.updateInterval)
-		defer ticker.Stop()
+		timer := time.NewTimer(p.untilNext())
+		defer timer.Stop()
 
 		for {
+			p.heartbeat.Store(time.Now().UnixNano())
+			timer.Reset(min(p.untilNext(), heartbeatInterval))
+
 			select {
-			case <-ticker.C:
-				p.coins.Range(func(key, value any) bool {
-					select {
-					case taskChan <- value.(entity.Cryptocurrency):
-						return true
-					case <-p.done:
-						return false
-					}
-				})
+			case <-timer.C:
+				due := p.popDue(time.Now())
+				if !p.leader.IsLeader() {
+					continue
+				}
+				if !p.dispatch(taskChan, due) {
+					close(taskChan)
+					return
+				}
+			case <-p.wake:
 			case <-p.done:
 				close(taskChan)
 				return
@@ -96,56 +157,503 @@ func (p *Parser) Start() {
 	}()
 }
 
-func (p *Parser) initWorkers(taskChan chan entity.Cryptocurrency) {
-	const op = "Parser.Workers"
-	log := p.log.With(slog.String("op", op))
+// dispatch queues polls of due coins as children of a root span of the tick
+// and reports false when the parser stops meanwhile.
+func (p *Parser) dispatch(taskChan chan task, due []entity.TrackedCoin) bool {
+	if len(due) == 0 {
+		return true
+	}
+
+	_, span := tracer.Start(context.Background(), "Parser.tick",
+		trace.WithNewRoot(),
+		trace.WithAttributes(attribute.Int("parser.due_coins", len(due))))
+	defer span.End()
+
+	for _, coin := range due {
+		select {
+		case taskChan <- task{coin: coin.Cryptocurrency, span: span.SpanContext()}:
+		case <-p.done:
+			return false
+		}
+	}
+	return true
+}
+
+// untilNext returns the time left until the earliest coin is due.
+func (p *Parser) untilNext() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.queue) == 0 {
+		return p.updateInterval
+	}
+	return time.Until(p.queue[0].due)
+}
+
+// popDue returns coins due at now, higher priority first, and schedules
+// their next polls. Polls missed by more than an interval are skipped, and
+// paused coins keep their schedule without being returned.
+func (p *Parser) popDue(now time.Time) []entity.TrackedCoin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var due []entity.TrackedCoin
+	for len(p.queue) > 0 && !p.queue[0].due.After(now) {
+		sc := p.queue[0]
+		if !p.paused && !sc.paused {
+			due = append(due, sc.coin)
+		}
+
+		interval := p.interval(sc.coin)
+		sc.due = sc.due.Add(interval)
+		if !sc.due.After(now) {
+			if p.leader.IsLeader() && !p.paused && !sc.paused {
+				missed := now.Sub(sc.due)/interval + 1
+				droppedTicks.WithLabelValues(sc.coin.Symbol).Add(float64(missed))
+			}
+			sc.due = now.Add(interval)
+		}
+		heap.Fix(&p.queue, 0)
+	}
+
+	slices.SortStableFunc(due, func(a, b entity.TrackedCoin) int {
+		return b.Priority - a.Priority
+	})
+	return due
+}
+
+// interval returns the polling interval of the coin, which is the default
+// one for coins without own interval. It must be called with mu held.
+func (p *Parser) interval(coin entity.TrackedCoin) time.Duration {
+	if coin.PollInterval <= 0 {
+		return p.updateInterval
+	}
+	return coin.PollInterval
+}
+
+// schedule adds the coin or updates its schedule and reports whether it was
+// added. It must be called with mu held.
+func (p *Parser) schedule(coin entity.TrackedCoin, now time.Time) bool {
+	interval := p.interval(coin)
+
+	if sc, ok := p.coins[coin.Symbol]; ok {
+		sc.coin = coin
+		if next := now.Add(interval); next.Before(sc.due) {
+			sc.due = next
+		}
+		heap.Fix(&p.queue, sc.index)
+		return false
+	}
+
+	sc := &scheduledCoin{coin: coin, due: now.Add(phase(coin.Symbol, interval)), added: now}
+	p.coins[coin.Symbol] = sc
+	heap.Push(&p.queue, sc)
+	return true
+}
+
+// unschedule removes the coin and reports whether it was scheduled. It must
+// be called with mu held.
+func (p *Parser) unschedule(symbol string) bool {
+	sc, ok := p.coins[symbol]
+	if !ok {
+		return false
+	}
 
-	for i := 0; i < p.maxWorkers; i++ {
+	heap.Remove(&p.queue, sc.index)
+	delete(p.coins, symbol)
+	return true
+}
+
+// notify wakes the dispatch loop to pick up schedule changes.
+func (p *Parser) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Parser) initWorkers(taskChan chan task) {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+
+	p.tasks = taskChan
+	p.resize()
+}
+
+// resize starts or stops workers to run maxWorkers of them. Stopped workers
+// finish their current poll first. It must be called with wmu held.
+func (p *Parser) resize() {
+	for len(p.quits) < p.maxWorkers {
+		quit := make(chan struct{})
+		p.quits = append(p.quits, quit)
 		p.wg.Add(1)
-		go func(workerID int) {
-			defer p.wg.Done()
-			for coin := range taskChan {
-				newPrice, err := p.cryptoClient.GetPrice(coin.Symbol, "USDT")
-				if err != nil {
-					log.Error(fmt.Sprintf("[Worker %d] Error fetching %s: %v\n", workerID, coin.Symbol, err))
-					continue
-				}
+		go p.work(len(p.quits)-1, quit)
+	}
 
-				ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
-				defer done()
-				hist := &entity.PriceHistory{
-					CryptocurrencyID: coin.ID,
-					Price:            newPrice,
-					Timestamp:        time.Now(),
-				}
-				_, err = p.hst.Create(ctx, hist)
-				if err != nil {
-					log.Error(fmt.Sprintf("[Worker %d] Error creating price history %s: %v\n", workerID, coin.Symbol, err))
-					continue
-				}
+	for len(p.quits) > p.maxWorkers {
+		last := len(p.quits) - 1
+		close(p.quits[last])
+		p.quits = p.quits[:last]
+	}
+}
 
-				log.Info(fmt.Sprintf("[Worker %d] Updated %s: %.2f\n", workerID, coin.Symbol, newPrice))
+func (p *Parser) work(workerID int, quit chan struct{}) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case t, ok := <-p.tasks:
+			if !ok {
+				return
 			}
-		}(i)
+			p.poll(workerID, t)
+		case <-quit:
+			return
+		}
 	}
 }
 
+func (p *Parser) poll(workerID int, t task) {
+	const op = "Parser.Workers"
+	log := p.log.With(slog.String("op", op))
+
+	coin := t.coin
+	// Tasks queued before losing leadership are dropped, followers are read-only.
+	if !p.leader.IsLeader() {
+		log.Debug(fmt.Sprintf("[Worker %d] Dropped poll of %s on follower", workerID, coin.Symbol))
+		return
+	}
+
+	ctx, span := tracer.Start(trace.ContextWithSpanContext(context.Background(), t.span), "Parser.poll",
+		trace.WithAttributes(
+			attribute.String("symbol", coin.Symbol),
+			attribute.Int("parser.worker", workerID),
+		))
+	defer span.End()
+
+	exchange := p.cryptoClient.Name()
+	start := time.Now()
+	newPrice, err := p.cryptoClient.GetPrice(ctx, coin.Symbol, p.quote)
+	latency := time.Since(start)
+	fetchDuration.WithLabelValues(exchange, coin.Symbol).Observe(latency.Seconds())
+	if err != nil {
+		log.Error(fmt.Sprintf("[Worker %d] Error fetching %s: %v\n", workerID, coin.Symbol, err))
+		fetchErrors.WithLabelValues(exchange, coin.Symbol).Inc()
+		span.SetStatus(codes.Error, err.Error())
+		p.record(coin.Symbol, latency, err)
+		return
+	}
+
+	ctx, done := context.WithTimeout(ctx, 5*time.Second)
+	defer done()
+	hist := &entity.PriceHistory{
+		CryptocurrencyID: coin.ID,
+		Price:            newPrice,
+		Timestamp:        time.Now(),
+	}
+	start = time.Now()
+	_, err = p.hst.Create(ctx, hist)
+	insertDuration.Observe(time.Since(start).Seconds())
+	if errors.Is(err, common.ErrHistoryAlreadyExists) {
+		log.Debug(fmt.Sprintf("[Worker %d] Skipped duplicate price of %s, %d skipped in total\n",
+			workerID, coin.Symbol, p.skipped.Add(1)))
+		samplesSkipped.WithLabelValues(coin.Symbol).Inc()
+		p.record(coin.Symbol, latency, nil)
+		return
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("[Worker %d] Error creating price history %s: %v\n", workerID, coin.Symbol, err))
+		insertErrors.WithLabelValues(coin.Symbol).Inc()
+		span.SetStatus(codes.Error, err.Error())
+		p.record(coin.Symbol, latency, err)
+		return
+	}
+
+	samplesWritten.WithLabelValues(coin.Symbol).Inc()
+	p.record(coin.Symbol, latency, nil)
+	log.Info(fmt.Sprintf("[Worker %d] Updated %s: %s\n", workerID, coin.Symbol, newPrice))
+}
+
+// record keeps the outcome of a poll of the coin and its fetch latency.
+func (p *Parser) record(symbol string, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sc, ok := p.coins[symbol]
+	if !ok {
+		return
+	}
+
+	sc.latency = latency
+	if err != nil {
+		sc.errors++
+		sc.lastError = err.Error()
+		sc.lastErrorAt = time.Now()
+		return
+	}
+	sc.lastSuccess = time.Now()
+}
+
+// Status returns the state of the parser and its coins ordered by symbol.
+func (p *Parser) Status() entity.ParserStatus {
+	p.wmu.Lock()
+	status := entity.ParserStatus{
+		Leader:        p.leader.IsLeader(),
+		Workers:       p.maxWorkers,
+		QueueDepth:    len(p.tasks),
+		QueueCapacity: cap(p.tasks),
+		Skipped:       p.skipped.Load(),
+	}
+	p.wmu.Unlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status.Paused = p.paused
+	status.Coins = make([]entity.CoinStatus, 0, len(p.coins))
+	for _, sc := range p.coins {
+		coin := sc.coin
+		coin.PollInterval = p.interval(coin)
+		status.Coins = append(status.Coins, entity.CoinStatus{
+			TrackedCoin: coin,
+			Paused:      sc.paused,
+			Added:       sc.added,
+			NextPoll:    sc.due,
+			LastSuccess: sc.lastSuccess,
+			LastError:   sc.lastError,
+			LastErrorAt: sc.lastErrorAt,
+			Errors:      sc.errors,
+			Latency:     sc.latency,
+		})
+	}
+	slices.SortFunc(status.Coins, func(a, b entity.CoinStatus) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+
+	return status
+}
+
+// Pause stops polling the coin until it is resumed. Tracking of the coin is
+// not changed, and the pause is kept by this replica only and lost on
+// restart. It fails on followers, where a pause would have no effect.
+func (p *Parser) Pause(symbol string) error {
+	return p.setPaused(symbol, true)
+}
+
+// Resume continues polling of the paused coin.
+func (p *Parser) Resume(symbol string) error {
+	return p.setPaused(symbol, false)
+}
+
+func (p *Parser) setPaused(symbol string, paused bool) error {
+	const op = "Parser.setPaused"
+
+	if !p.leader.IsLeader() {
+		return fmt.Errorf("%s: %w", op, common.ErrParserNotLeader)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sc, ok := p.coins[symbol]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, common.ErrParserCoinNotFound)
+	}
+	sc.paused = paused
+
+	return nil
+}
+
+// PauseAll stops polling of all coins until ResumeAll. It fails on
+// followers like Pause.
+func (p *Parser) PauseAll() error {
+	const op = "Parser.PauseAll"
+
+	if !p.leader.IsLeader() {
+		return fmt.Errorf("%s: %w", op, common.ErrParserNotLeader)
+	}
+
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+
+	return nil
+}
+
+// ResumeAll continues polling of all coins including individually paused ones.
+func (p *Parser) ResumeAll() error {
+	const op = "Parser.ResumeAll"
+
+	if !p.leader.IsLeader() {
+		return fmt.Errorf("%s: %w", op, common.ErrParserNotLeader)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.paused = false
+	for _, sc := range p.coins {
+		sc.paused = false
+	}
+
+	return nil
+}
+
+// Poll queues an immediate poll of the coin regardless of its schedule and
+// pause, traced as a child of the span of ctx. It fails on followers, which
+// do not write prices, and when the task queue is full.
+func (p *Parser) Poll(ctx context.Context, symbol string) error {
+	const op = "Parser.Poll"
+
+	if !p.leader.IsLeader() {
+		return fmt.Errorf("%s: %w", op, common.ErrParserNotLeader)
+	}
+
+	p.mu.Lock()
+	sc, ok := p.coins[symbol]
+	var coin entity.Cryptocurrency
+	if ok {
+		coin = sc.coin.Cryptocurrency
+	}
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%s: %w", op, common.ErrParserCoinNotFound)
+	}
+
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	if p.tasks == nil || p.stopped {
+		return fmt.Errorf("%s: %w", op, common.ErrParserQueueFull)
+	}
+
+	select {
+	case p.tasks <- task{coin: coin, span: trace.SpanContextFromContext(ctx)}:
+		return nil
+	default:
+		return fmt.Errorf("%s: %w", op, common.ErrParserQueueFull)
+	}
+}
+
+// SetWorkers changes the number of concurrent polls.
+func (p *Parser) SetWorkers(n int) {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+
+	p.maxWorkers = n
+	if p.tasks != nil && !p.stopped {
+		p.resize()
+	}
+}
+
+// SetInterval changes the polling interval of coins without own one. Their
+// next polls are brought forward to the new interval if it is shorter.
+func (p *Parser) SetInterval(d time.Duration) {
+	p.mu.Lock()
+	p.updateInterval = d
+	now := time.Now()
+	for _, sc := range p.coins {
+		if sc.coin.PollInterval > 0 {
+			continue
+		}
+		if next := now.Add(d); next.Before(sc.due) {
+			sc.due = next
+			heap.Fix(&p.queue, sc.index)
+		}
+	}
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Heartbeat returns when the dispatch loop last ran, or the zero time before
+// the parser started.
+func (p *Parser) Heartbeat() time.Time {
+	nano := p.heartbeat.Load()
+	if nano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nano)
+}
+
 func (p *Parser) Stop() {
 	p.log.Info("Stop parsing")
+	p.wmu.Lock()
+	p.stopped = true
+	p.wmu.Unlock()
 	close(p.done)
 	p.wg.Wait()
 }
 
-func (p *Parser) AddCoin(c entity.Cryptocurrency) {
+func (p *Parser) AddCoin(c entity.TrackedCoin) {
 	const op = "Parser.AddCoin"
 	log := p.log.With(slog.String("op", op))
-	p.coins.Store(c.Symbol, c)
+
+	p.mu.Lock()
+	p.schedule(c, time.Now())
+	p.changed(c.Symbol)
+	p.mu.Unlock()
+	p.notify()
+
 	log.Info(fmt.Sprintf("Coin %s added to parser", c.Symbol))
 }
 
+// changed records a change of the coin by AddCoin or RemoveCoin. It must be
+// called with mu held.
+func (p *Parser) changed(symbol string) {
+	p.version++
+	p.changes[symbol] = p.version
+}
+
+// Resync replaces the coin set with active trackings. Coins added or removed
+// while active trackings are read are left as they are, as the read may
+// predate their change.
+func (p *Parser) Resync(ctx context.Context) error {
+	const op = "Parser.Resync"
+	log := p.log.With(slog.String("op", op))
+
+	p.mu.Lock()
+	snapshot := p.version
+	p.mu.Unlock()
+
+	crs, err := p.cst.GetActive(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	p.mu.Lock()
+	defer p.notify()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	active := make(map[string]bool, len(crs))
+	for _, cr := range crs {
+		active[cr.Symbol] = true
+		if p.changes[cr.Symbol] > snapshot {
+			continue
+		}
+		if p.schedule(cr, now) {
+			log.Info(fmt.Sprintf("Coin %s added to parser on resync", cr.Symbol))
+		}
+	}
+
+	for symbol := range p.coins {
+		if !active[symbol] && p.changes[symbol] <= snapshot {
+			p.unschedule(symbol)
+			log.Info(fmt.Sprintf("Coin %s removed from parser on resync", symbol))
+		}
+	}
+
+	return nil
+}
+
 func (p *Parser) RemoveCoin(c entity.Cryptocurrency) {
 	const op = "Parser.RemoveCoin"
 	log := p.log.With(slog.String("op", op))
-	p.coins.Delete(c.Symbol)
+
+	p.mu.Lock()
+	p.unschedule(c.Symbol)
+	p.changed(c.Symbol)
+	p.mu.Unlock()
+	p.notify()
+
 	log.Info(fmt.Sprintf("Coin %s removed from parser", c.Symbol))
 }
@@ -0,0 +1,229 @@
+package background
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+)
+
+type fakeLeader bool
+
+func (l fakeLeader) IsLeader() bool { return bool(l) }
+
+// fakeCurrencyStorage returns active coins, calling during once while they
+// are read.
+type fakeCurrencyStorage struct {
+	active []entity.TrackedCoin
+	during func()
+}
+
+func (s *fakeCurrencyStorage) GetActive(context.Context) ([]entity.TrackedCoin, error) {
+	if s.during != nil {
+		s.during()
+		s.during = nil
+	}
+	return s.active, nil
+}
+
+var scheduleStart = time.Unix(1754578944, 0)
+
+func coin(symbol string, priority int) entity.TrackedCoin {
+	return entity.TrackedCoin{Cryptocurrency: entity.Cryptocurrency{Symbol: symbol}, Priority: priority}
+}
+
+func newTestParser(cst CurrencyStorage) *Parser {
+	return NewParser(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Minute, 1, "USDT",
+		nil, cst, nil, fakeLeader(true))
+}
+
+// scheduleAt schedules coins due after the offsets from scheduleStart.
+func (p *Parser) scheduleAt(t *testing.T, coins []entity.TrackedCoin, offsets ...time.Duration) {
+	t.Helper()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, c := range coins {
+		p.schedule(c, scheduleStart)
+		sc := p.coins[c.Symbol]
+		sc.due = scheduleStart.Add(offsets[i])
+		heap.Fix(&p.queue, sc.index)
+	}
+}
+
+func symbols(coins []entity.TrackedCoin) []string {
+	out := make([]string, 0, len(coins))
+	for _, c := range coins {
+		out = append(out, c.Symbol)
+	}
+	return out
+}
+
+func TestPopDue(t *testing.T) {
+	tests := []struct {
+		name    string
+		coins   []entity.TrackedCoin
+		offsets []time.Duration
+		now     time.Duration
+		want    []string
+	}{
+		{
+			name:    "only due coins in due order",
+			coins:   []entity.TrackedCoin{coin("A", 0), coin("B", 0), coin("C", 0)},
+			offsets: []time.Duration{2 * time.Second, time.Second, 3 * time.Second},
+			now:     2 * time.Second,
+			want:    []string{"B", "A"},
+		},
+		{
+			name:    "higher priority first",
+			coins:   []entity.TrackedCoin{coin("A", 1), coin("B", 5), coin("C", 3)},
+			offsets: []time.Duration{0, time.Second, 0},
+			now:     time.Second,
+			want:    []string{"B", "C", "A"},
+		},
+		{
+			name:    "priority ties keep due order",
+			coins:   []entity.TrackedCoin{coin("A", 2), coin("B", 2), coin("C", 2)},
+			offsets: []time.Duration{2 * time.Second, 0, time.Second},
+			now:     2 * time.Second,
+			want:    []string{"B", "C", "A"},
+		},
+		{
+			name:    "none due",
+			coins:   []entity.TrackedCoin{coin("A", 0)},
+			offsets: []time.Duration{time.Second},
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestParser(nil)
+			p.scheduleAt(t, tt.coins, tt.offsets...)
+
+			got := symbols(p.popDue(scheduleStart.Add(tt.now)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPopDueReschedules(t *testing.T) {
+	p := newTestParser(nil)
+	p.scheduleAt(t, []entity.TrackedCoin{coin("A", 0), coin("B", 0)}, 0, -time.Hour)
+
+	now := scheduleStart.Add(time.Second)
+	p.popDue(now)
+
+	// A polled on time is due an interval after its previous due time, B
+	// missed many polls and is due an interval from now instead.
+	if due := p.coins["A"].due; !due.Equal(scheduleStart.Add(time.Minute)) {
+		t.Errorf("A is due at %s, want %s", due, scheduleStart.Add(time.Minute))
+	}
+	if due := p.coins["B"].due; !due.Equal(now.Add(time.Minute)) {
+		t.Errorf("B is due at %s, want %s", due, now.Add(time.Minute))
+	}
+	if got := p.popDue(now); len(got) != 0 {
+		t.Errorf("got %v due again, want none", symbols(got))
+	}
+}
+
+func TestPopDuePaused(t *testing.T) {
+	p := newTestParser(nil)
+	p.scheduleAt(t, []entity.TrackedCoin{coin("A", 0), coin("B", 0)}, 0, 0)
+
+	if err := p.Pause("A"); err != nil {
+		t.Fatal(err)
+	}
+	if got := symbols(p.popDue(scheduleStart)); !slices.Equal(got, []string{"B"}) {
+		t.Errorf("got %v, want [B]", got)
+	}
+	// Paused coins keep their schedule.
+	if due := p.coins["A"].due; !due.Equal(scheduleStart.Add(time.Minute)) {
+		t.Errorf("paused A is due at %s, want %s", due, scheduleStart.Add(time.Minute))
+	}
+
+	if err := p.PauseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.popDue(scheduleStart.Add(time.Minute)); len(got) != 0 {
+		t.Errorf("got %v while all paused, want none", symbols(got))
+	}
+
+	if err := p.ResumeAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := symbols(p.popDue(scheduleStart.Add(2 * time.Minute))); !slices.Equal(got, []string{"A", "B"}) {
+		t.Errorf("got %v after resume, want [A B]", got)
+	}
+}
+
+func TestPauseFollower(t *testing.T) {
+	p := newTestParser(nil)
+	p.leader = fakeLeader(false)
+	p.scheduleAt(t, []entity.TrackedCoin{coin("A", 0)}, 0)
+
+	// Followers do not poll, so pauses are rejected rather than lost.
+	for name, fn := range map[string]func() error{
+		"Pause":     func() error { return p.Pause("A") },
+		"Resume":    func() error { return p.Resume("A") },
+		"PauseAll":  p.PauseAll,
+		"ResumeAll": p.ResumeAll,
+	} {
+		if err := fn(); !errors.Is(err, common.ErrParserNotLeader) {
+			t.Errorf("%s got error %v, want %v", name, err, common.ErrParserNotLeader)
+		}
+	}
+	if p.paused || p.coins["A"].paused {
+		t.Error("follower paused polling")
+	}
+}
+
+func TestResync(t *testing.T) {
+	cst := &fakeCurrencyStorage{}
+	p := newTestParser(cst)
+	p.scheduleAt(t, []entity.TrackedCoin{coin("KEPT", 0), coin("GONE", 0), coin("REMOVED", 0)}, 0, 0, 0)
+
+	// The read predates REMOVED being removed and ADDED being added.
+	cst.active = []entity.TrackedCoin{coin("KEPT", 7), coin("REMOVED", 0), coin("NEW", 0)}
+	cst.during = func() {
+		p.RemoveCoin(entity.Cryptocurrency{Symbol: "REMOVED"})
+		p.AddCoin(coin("ADDED", 0))
+	}
+
+	if err := p.Resync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, 0, len(p.coins))
+	for symbol := range p.coins {
+		got = append(got, symbol)
+	}
+	slices.Sort(got)
+	if want := []string{"ADDED", "KEPT", "NEW"}; !slices.Equal(got, want) {
+		t.Errorf("got coins %v, want %v", got, want)
+	}
+	if priority := p.coins["KEPT"].coin.Priority; priority != 7 {
+		t.Errorf("KEPT has priority %d, want 7", priority)
+	}
+	if len(p.queue) != len(p.coins) {
+		t.Errorf("got %d scheduled coins, want %d", len(p.queue), len(p.coins))
+	}
+
+	// Later resyncs apply the stored state again.
+	cst.active = []entity.TrackedCoin{coin("KEPT", 7)}
+	if err := p.Resync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.coins) != 1 || p.coins["KEPT"] == nil {
+		t.Errorf("got %d coins after second resync, want only KEPT", len(p.coins))
+	}
+}
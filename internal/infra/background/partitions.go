@@ -0,0 +1,91 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// leaderCheckInterval bounds how long a new leader waits before its first
+// partition maintenance or archiving.
+const leaderCheckInterval = 30 * time.Second
+
+type PartitionMaintainer interface {
+	Maintain(ctx context.Context) error
+}
+
+// PartitionManager periodically pre-creates and expires price history
+// partitions. Only the leader maintains them, right after becoming leader
+// and then every interval.
+type PartitionManager struct {
+	log        *slog.Logger
+	interval   time.Duration
+	maintainer PartitionMaintainer
+	leader     Leader
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+func NewPartitionManager(
+	log *slog.Logger,
+	interval time.Duration,
+	maintainer PartitionMaintainer,
+	leader Leader,
+) *PartitionManager {
+	return &PartitionManager{
+		log:        log,
+		interval:   interval,
+		maintainer: maintainer,
+		leader:     leader,
+		done:       make(chan struct{}),
+	}
+}
+
+func (m *PartitionManager) Start() {
+	m.log.Info("Start partition maintenance")
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(min(m.interval, leaderCheckInterval))
+		defer ticker.Stop()
+
+		var last time.Time
+		for {
+			switch {
+			case !m.leader.IsLeader():
+				last = time.Time{}
+			case time.Since(last) >= m.interval:
+				m.maintain()
+				last = time.Now()
+			}
+
+			select {
+			case <-ticker.C:
+			case <-m.done:
+				return
+			}
+		}
+	}()
+}
+
+func (m *PartitionManager) maintain() {
+	const op = "PartitionManager.maintain"
+	log := m.log.With(slog.String("op", op))
+
+	ctx, done := context.WithTimeout(context.Background(), time.Minute)
+	defer done()
+
+	if err := m.maintainer.Maintain(ctx); err != nil {
+		log.Error(fmt.Sprintf("Error maintaining partitions: %v", err))
+	}
+}
+
+func (m *PartitionManager) Stop() {
+	m.log.Info("Stop partition maintenance")
+	close(m.done)
+	m.wg.Wait()
+}
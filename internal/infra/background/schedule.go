@@ -0,0 +1,65 @@
+package background
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+)
+
+// scheduledCoin is a coin waiting in the schedule for its next poll along
+// with the outcome of its recent polls.
+type scheduledCoin struct {
+	coin        entity.TrackedCoin
+	due         time.Time
+	index       int
+	paused      bool
+	added       time.Time
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+	errors      int64
+	latency     time.Duration
+}
+
+// schedule is a min-heap of coins ordered by due time and then by descending
+// priority. It implements heap.Interface.
+type schedule []*scheduledCoin
+
+func (s schedule) Len() int { return len(s) }
+
+func (s schedule) Less(i, j int) bool {
+	if !s[i].due.Equal(s[j].due) {
+		return s[i].due.Before(s[j].due)
+	}
+	return s[i].coin.Priority > s[j].coin.Priority
+}
+
+func (s schedule) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	s[i].index = i
+	s[j].index = j
+}
+
+func (s *schedule) Push(x any) {
+	sc := x.(*scheduledCoin)
+	sc.index = len(*s)
+	*s = append(*s, sc)
+}
+
+func (s *schedule) Pop() any {
+	old := *s
+	n := len(old)
+	sc := old[n-1]
+	old[n-1] = nil
+	*s = old[:n-1]
+	return sc
+}
+
+// phase offsets the first poll of a coin within its interval by a hash of the
+// symbol, so coins sharing an interval are spread instead of fired together.
+func phase(symbol string, interval time.Duration) time.Duration {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(symbol))
+	return time.Duration(uint64(h.Sum32()) % uint64(interval))
+}
@@ -0,0 +1,79 @@
+// Package fx implements offline sources of fiat exchange rates.
+package fx
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+const dateLayout = "2006-01-02"
+
+// CSVSource reads rates from a file with "date,currency,rate" rows.
+// A header row is optional.
+type CSVSource struct {
+	path string
+}
+
+func NewCSVSource(path string) *CSVSource {
+	return &CSVSource{path: path}
+}
+
+func (s *CSVSource) Fetch(ctx context.Context) ([]entity.FXRate, error) {
+	const op = "CSVSource.Fetch"
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	rates := make([]entity.FXRate, 0)
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+		}
+
+		rate, err := decimal.NewFromString(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+		}
+
+		rates = append(rates, entity.FXRate{
+			Currency: strings.ToUpper(record[1]),
+			Date:     date,
+			Rate:     rate,
+		})
+	}
+
+	return rates, nil
+}
@@ -1,91 +1,160 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/Homyakadze14/AFFARM_tz/internal/infra/http")
+
 type BinanceClient struct {
 	log     *slog.Logger
 	client  *http.Client
 	baseURL string
+	quote   string
+	breaker *breaker
 }
 
-func NewBinanceClient(log *slog.Logger, timeout time.Duration) *BinanceClient {
+// NewBinanceClient creates the client of the Binance API at baseURL. Symbols
+// exist when they are traded against the quote currency. After failures
+// consecutive failed requests the client stops calling the API for cooldown.
+func NewBinanceClient(
+	log *slog.Logger,
+	baseURL, quote string,
+	timeout time.Duration,
+	failures int,
+	cooldown time.Duration,
+) *BinanceClient {
 	client := &http.Client{
 		Timeout: timeout,
 	}
 
-	baseURL := "https://api.binance.com/api/v3"
-
 	return &BinanceClient{
 		log:     log,
 		client:  client,
-		baseURL: baseURL,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		quote:   quote,
+		breaker: newBreaker(failures, cooldown),
 	}
 }
 
+// Name identifies the exchange.
+func (c *BinanceClient) Name() string {
+	return "binance"
+}
+
+// CircuitState returns the state of the circuit breaker of the API.
+func (c *BinanceClient) CircuitState() string {
+	return c.breaker.State()
+}
+
 type Cryptocurrency struct {
 	Price string `json:"price"`
 }
 
-func (c *BinanceClient) GetPrice(symbol string, currency string) (float64, error) {
+func (c *BinanceClient) GetPrice(ctx context.Context, symbol string, currency string) (decimal.Decimal, error) {
 	const op = "BinanceClient.GetPrice"
 	log := c.log.With(slog.String("op", op),
 		slog.String("symbol", symbol),
 		slog.String("currency", currency))
 
 	priceURL := c.baseURL + fmt.Sprintf("/ticker/price?symbol=%s%s", symbol, currency)
-	resp, err := c.client.Get(priceURL)
+	ctx, span := tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodGet,
+			semconv.URLFull(priceURL),
+			attribute.String("exchange", c.Name()),
+		))
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, priceURL, nil)
 	if err != nil {
+		log.Error(fmt.Sprintf("fail to create request! error: %s", err))
+		span.SetStatus(codes.Error, err.Error())
+		return decimal.Zero, common.ErrUnexpected
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	if !c.breaker.allow() {
+		log.Error("circuit is open, skipping request!")
+		span.SetStatus(codes.Error, common.ErrExchangeUnavailable.Error())
+		return decimal.Zero, common.ErrExchangeUnavailable
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.breaker.cancel()
+		} else {
+			c.breaker.record(false)
+		}
 		log.Error(fmt.Sprintf("fail to get price! error: %s", err))
-		return 0, common.ErrUnexpected
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return decimal.Zero, common.ErrExchangeFailed
 	}
 	defer resp.Body.Close()
 
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	c.breaker.record(resp.StatusCode < http.StatusInternalServerError &&
+		resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusTeapot)
 	if resp.StatusCode != http.StatusOK {
 		log.Error(fmt.Sprintf("bad status code! code: %s", resp.Status))
+		span.SetStatus(codes.Error, resp.Status)
 		if resp.StatusCode == http.StatusBadRequest {
-			return 0, common.ErrBadData
+			return decimal.Zero, common.ErrBadData
 		}
-		return 0, common.ErrUnexpected
+		return decimal.Zero, common.ErrExchangeFailed
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		log.Error(fmt.Sprintf("fail to read response body! error: %s", err))
-		return 0, common.ErrUnexpected
+		span.SetStatus(codes.Error, err.Error())
+		return decimal.Zero, common.ErrExchangeFailed
 	}
 
 	cryptocur := &Cryptocurrency{}
 	err = json.Unmarshal(data, cryptocur)
 	if err != nil {
 		log.Error(fmt.Sprintf("fail to unmarshal response body! error: %s", err))
-		return 0, common.ErrUnexpected
+		span.SetStatus(codes.Error, err.Error())
+		return decimal.Zero, common.ErrExchangeFailed
 	}
 
-	price, err := strconv.ParseFloat(cryptocur.Price, 64)
+	price, err := decimal.NewFromString(cryptocur.Price)
 	if err != nil {
-		return 0, err
+		log.Error(fmt.Sprintf("fail to parse price! error: %s", err))
+		span.SetStatus(codes.Error, err.Error())
+		return decimal.Zero, common.ErrExchangeFailed
 	}
 
 	return price, nil
 }
 
-func (c *BinanceClient) SymbolExists(symbol string) (bool, error) {
+func (c *BinanceClient) SymbolExists(ctx context.Context, symbol string) (bool, error) {
 	const op = "BinanceClient.SymbolExists"
 	log := c.log.With(slog.String("op", op),
 		slog.String("symbol", symbol))
 
-	_, err := c.GetPrice(symbol, "USDT")
+	_, err := c.GetPrice(ctx, symbol, c.quote)
 	if err != nil {
 		if errors.Is(err, common.ErrBadData) {
 			return false, nil
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+)
+
+// breaker stops requests to a failing upstream. It opens after threshold
+// consecutive failures and after cooldown lets a single request probe the
+// upstream, closing on its success and reopening on its failure.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a request may be sent now.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state() {
+	case entity.CircuitClosed:
+		return true
+	case entity.CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return false
+}
+
+// record counts the outcome of an allowed request.
+func (b *breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if ok {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// cancel releases the probe of a request abandoned by the caller without
+// counting it.
+func (b *breaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State returns one of entity.CircuitClosed, entity.CircuitOpen or
+// entity.CircuitHalfOpen.
+func (b *breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state()
+}
+
+func (b *breaker) state() string {
+	if b.openedAt.IsZero() {
+		return entity.CircuitClosed
+	}
+	if time.Since(b.openedAt) < b.cooldown {
+		return entity.CircuitOpen
+	}
+	return entity.CircuitHalfOpen
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+)
+
+func assertState(t *testing.T, b *breaker, want string) {
+	t.Helper()
+
+	if got := b.State(); got != want {
+		t.Fatalf("got state %s, want %s", got, want)
+	}
+}
+
+// cooledDown makes the open breaker due for a probe.
+func cooledDown(b *breaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.openedAt = time.Now().Add(-b.cooldown)
+}
+
+func TestBreakerOpens(t *testing.T) {
+	b := newBreaker(3, time.Hour)
+
+	b.record(false)
+	b.record(false)
+	b.record(true)
+	b.record(false)
+	b.record(false)
+	// A success resets consecutive failures.
+	assertState(t, b, entity.CircuitClosed)
+	if !b.allow() {
+		t.Fatal("closed breaker rejected a request")
+	}
+
+	b.record(false)
+	assertState(t, b, entity.CircuitOpen)
+	if b.allow() {
+		t.Fatal("open breaker allowed a request")
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(b *breaker)
+		want   string
+		allow  bool
+	}{
+		{
+			name:   "success closes",
+			finish: func(b *breaker) { b.record(true) },
+			want:   entity.CircuitClosed,
+			allow:  true,
+		},
+		{
+			name:   "failure reopens",
+			finish: func(b *breaker) { b.record(false) },
+			want:   entity.CircuitOpen,
+			allow:  false,
+		},
+		{
+			name:   "cancel releases probe",
+			finish: func(b *breaker) { b.cancel() },
+			want:   entity.CircuitHalfOpen,
+			allow:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBreaker(1, time.Hour)
+			b.record(false)
+			cooledDown(b)
+			assertState(t, b, entity.CircuitHalfOpen)
+
+			if !b.allow() {
+				t.Fatal("half-open breaker rejected the probe")
+			}
+			if b.allow() {
+				t.Fatal("half-open breaker allowed a second probe")
+			}
+
+			tt.finish(b)
+			assertState(t, b, tt.want)
+			if got := b.allow(); got != tt.allow {
+				t.Errorf("allow = %t, want %t", got, tt.allow)
+			}
+		})
+	}
+}
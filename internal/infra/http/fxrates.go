@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeRateClient fetches daily fiat rates from open.er-api.com against
+// base, the fiat currency the quote currency is or is pegged to, so rates of
+// stablecoin quotes ignore their deviation from the peg.
+type ExchangeRateClient struct {
+	log        *slog.Logger
+	client     *http.Client
+	baseURL    string
+	base       string
+	currencies []string
+}
+
+func NewExchangeRateClient(log *slog.Logger, timeout time.Duration, base string, currencies []string) *ExchangeRateClient {
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	baseURL := "https://open.er-api.com/v6"
+
+	return &ExchangeRateClient{
+		log:        log,
+		client:     client,
+		baseURL:    baseURL,
+		base:       base,
+		currencies: currencies,
+	}
+}
+
+type latestRates struct {
+	Result         string                     `json:"result"`
+	TimeLastUpdate int64                      `json:"time_last_update_unix"`
+	Rates          map[string]decimal.Decimal `json:"rates"`
+}
+
+func (c *ExchangeRateClient) Fetch(ctx context.Context) ([]entity.FXRate, error) {
+	const op = "ExchangeRateClient.Fetch"
+	log := c.log.With(slog.String("op", op))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/latest/"+c.base, nil)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to build request! error: %s", err))
+		return nil, common.ErrUnexpected
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get rates! error: %s", err))
+		return nil, common.ErrUnexpected
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Error(fmt.Sprintf("bad status code! code: %s", resp.Status))
+		return nil, common.ErrUnexpected
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to read response body! error: %s", err))
+		return nil, common.ErrUnexpected
+	}
+
+	latest := &latestRates{}
+	err = json.Unmarshal(data, latest)
+	if err != nil || latest.Result != "success" {
+		log.Error(fmt.Sprintf("fail to unmarshal response body! error: %v", err))
+		return nil, common.ErrUnexpected
+	}
+
+	date := time.Unix(latest.TimeLastUpdate, 0).UTC().Truncate(24 * time.Hour)
+	rates := make([]entity.FXRate, 0, len(c.currencies))
+	for _, cur := range c.currencies {
+		rate, ok := latest.Rates[cur]
+		if !ok {
+			log.Error(fmt.Sprintf("currency %s is missing in response", cur))
+			continue
+		}
+
+		rates = append(rates, entity.FXRate{
+			Currency: cur,
+			Date:     date,
+			Rate:     rate,
+		})
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+const archiveDefaultSliceCap = 16
+
+type ArchiveRepo struct {
+	*postgres.Postgres
+}
+
+func NewArchiveRepository(pg *postgres.Postgres) *ArchiveRepo {
+	return &ArchiveRepo{pg}
+}
+
+// Pending returns months of price history before the given time which are
+// not archived yet, one per cryptocurrency, oldest first.
+func (r *ArchiveRepo) Pending(ctx context.Context, before time.Time) ([]entity.Archive, error) {
+	const op = "ArchiveRepo.Pending"
+	const query = `SELECT h.cryptocurrency_id, date_trunc('month', h.timestamp, 'UTC') AS period_start, count(*)
+                   FROM price_history AS h
+                   WHERE h.timestamp < $1
+                     AND NOT EXISTS (
+                       SELECT 1 FROM price_archives AS a
+                       WHERE a.cryptocurrency_id = h.cryptocurrency_id
+                         AND a.period_start = date_trunc('month', h.timestamp, 'UTC')
+                     )
+                   GROUP BY 1, 2
+                   ORDER BY 2, 1`
+
+	rows, err := r.Pool.Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	archives := make([]entity.Archive, 0, archiveDefaultSliceCap)
+	for rows.Next() {
+		var a entity.Archive
+
+		if err := rows.Scan(&a.CryptocurrencyID, &a.PeriodStart, &a.Rows); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		a.PeriodStart = a.PeriodStart.UTC()
+		a.PeriodEnd = a.PeriodStart.AddDate(0, 1, 0)
+
+		archives = append(archives, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return archives, nil
+}
+
+// Commit records the archive in the manifest and deletes archived samples
+// in one transaction. It fails with common.ErrArchiveConflict when samples
+// of the period changed after they were written to the archive.
+func (r *ArchiveRepo) Commit(ctx context.Context, a *entity.Archive) error {
+	const op = "ArchiveRepo.Commit"
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO price_archives (cryptocurrency_id, period_start, period_end, object_key, rows)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at;`, a.CryptocurrencyID, a.PeriodStart, a.PeriodEnd, a.Key, a.Rows).
+		Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag, err := tx.Exec(ctx,
+		`DELETE FROM price_history
+		WHERE cryptocurrency_id = $1 AND timestamp >= $2 AND timestamp < $3`,
+		a.CryptocurrencyID, a.PeriodStart, a.PeriodEnd)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() != a.Rows {
+		return fmt.Errorf("%s: %w", op, common.ErrArchiveConflict)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetOverlapping returns archives of the cryptocurrency overlapping [from, to].
+func (r *ArchiveRepo) GetOverlapping(ctx context.Context, cryptocurrencyID int, from, to time.Time) ([]entity.Archive, error) {
+	const op = "ArchiveRepo.GetOverlapping"
+	const query = `SELECT id, cryptocurrency_id, period_start, period_end, object_key, rows, created_at
+                   FROM price_archives
+                   WHERE cryptocurrency_id = $1 AND period_start <= $3 AND period_end > $2
+                   ORDER BY period_start`
+
+	archives, err := r.query(ctx, query, cryptocurrencyID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return archives, nil
+}
+
+// GetAround returns the archive of the cryptocurrency covering the timestamp
+// and its nearest neighbours, which together hold the nearest archived sample.
+func (r *ArchiveRepo) GetAround(ctx context.Context, cryptocurrencyID int, timestamp time.Time) ([]entity.Archive, error) {
+	const op = "ArchiveRepo.GetAround"
+	const query = `(SELECT id, cryptocurrency_id, period_start, period_end, object_key, rows, created_at
+                    FROM price_archives
+                    WHERE cryptocurrency_id = $1 AND period_start <= $2
+                    ORDER BY period_start DESC
+                    LIMIT 2)
+                   UNION ALL
+                   (SELECT id, cryptocurrency_id, period_start, period_end, object_key, rows, created_at
+                    FROM price_archives
+                    WHERE cryptocurrency_id = $1 AND period_start > $2
+                    ORDER BY period_start
+                    LIMIT 1)`
+
+	archives, err := r.query(ctx, query, cryptocurrencyID, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return archives, nil
+}
+
+func (r *ArchiveRepo) query(ctx context.Context, query string, args ...any) ([]entity.Archive, error) {
+	rows, err := r.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Archive, error) {
+		var a entity.Archive
+		err := row.Scan(&a.ID, &a.CryptocurrencyID, &a.PeriodStart, &a.PeriodEnd, &a.Key, &a.Rows, &a.CreatedAt)
+		return a, err
+	})
+}
@@ -25,7 +25,7 @@ func NewCryptocurrencyRepository(pg *postgres.Postgres) *CryptocurRepo {
 func (r *CryptocurRepo) Create(ctx context.Context, cryptocur *entity.Cryptocurrency) (*entity.Cryptocurrency, error) {
 	const op = "CryptocurRepo.Create"
 
-	err := r.Pool.QueryRow(ctx,
+	err := conn(ctx, r.Postgres).QueryRow(ctx,
 		`INSERT INTO cryptocurrencies (symbol)
 		VALUES ($1)
 		RETURNING id;`, cryptocur.Symbol).Scan(&cryptocur.ID)
@@ -40,7 +40,7 @@ func (r *CryptocurRepo) Create(ctx context.Context, cryptocur *entity.Cryptocurr
 }
 
 func (r *CryptocurRepo) get(ctx context.Context, op string, condition string, args ...interface{}) (*entity.Cryptocurrency, error) {
-	row := r.Pool.QueryRow(ctx,
+	row := conn(ctx, r.Postgres).QueryRow(ctx,
 		fmt.Sprintf("SELECT id, symbol FROM cryptocurrencies WHERE %s", condition),
 		args...)
 
@@ -63,45 +63,72 @@ func (r *CryptocurRepo) GetBySymbol(ctx context.Context, symbol string) (*entity
 	return r.get(ctx, op, condition, symbol)
 }
 
+// CreateOrGet skips the insert on conflict instead of failing it, so it does
+// not abort the surrounding transaction.
 func (r *CryptocurRepo) CreateOrGet(ctx context.Context, c *entity.Cryptocurrency) (*entity.Cryptocurrency, error) {
 	const op = "CryptocurRepo.CreateOrGet"
 
-	cr, err := r.Create(ctx, c)
+	err := conn(ctx, r.Postgres).QueryRow(ctx,
+		`INSERT INTO cryptocurrencies (symbol)
+		VALUES ($1)
+		ON CONFLICT (symbol) DO NOTHING
+		RETURNING id;`, c.Symbol).Scan(&c.ID)
+	if err == nil {
+		return c, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cr, err := r.GetBySymbol(ctx, c.Symbol)
 	if err != nil {
-		if !errors.Is(err, common.ErrCryptocurrencyAlreadyExists) {
-			return nil, fmt.Errorf("%s: %w", op, err)
-		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-		cr, err = r.GetBySymbol(ctx, c.Symbol)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+	return cr, nil
+}
+
+// Lock locks the cryptocurrency row until the end of the transaction,
+// serializing tracking changes of the cryptocurrency.
+func (r *CryptocurRepo) Lock(ctx context.Context, id int) error {
+	const op = "CryptocurRepo.Lock"
+
+	var locked int
+	err := conn(ctx, r.Postgres).QueryRow(ctx,
+		`SELECT id FROM cryptocurrencies WHERE id=$1 FOR UPDATE`, id).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, common.ErrCryptocurrencyNotFound)
 		}
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return cr, nil
+	return nil
 }
 
-func (r *CryptocurRepo) GetActive(ctx context.Context) ([]entity.Cryptocurrency, error) {
+func (r *CryptocurRepo) GetActive(ctx context.Context) ([]entity.TrackedCoin, error) {
 	const op = "CryptocurRepo.GetActive"
 
-	query := `SELECT cr.id, symbol FROM cryptocurrencies AS cr 
+	query := `SELECT cr.id, symbol, t.poll_interval_ms, t.priority FROM cryptocurrencies AS cr 
 			LEFT JOIN trackings AS t ON cryptocurrency_id=cr.id WHERE t.is_active=true`
 
-	rows, err := r.Pool.Query(ctx, query)
+	rows, err := conn(ctx, r.Postgres).Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	crs := make([]entity.Cryptocurrency, 0, trackDefaultSliceCap)
+	crs := make([]entity.TrackedCoin, 0, trackDefaultSliceCap)
 	for rows.Next() {
-		var cr entity.Cryptocurrency
+		var cr entity.TrackedCoin
+		var interval *int64
 
 		err := rows.Scan(
-			&cr.ID, &cr.Symbol,
+			&cr.ID, &cr.Symbol, &interval, &cr.Priority,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
+		cr.PollInterval = pollInterval(interval)
 
 		crs = append(crs, cr)
 	}
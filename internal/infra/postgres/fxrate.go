@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+type FXRateRepo struct {
+	*postgres.Postgres
+}
+
+func NewFXRateRepository(pg *postgres.Postgres) *FXRateRepo {
+	return &FXRateRepo{pg}
+}
+
+func (r *FXRateRepo) Upsert(ctx context.Context, rates []entity.FXRate) (int, error) {
+	const op = "FXRateRepo.Upsert"
+
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(
+			`INSERT INTO fx_rates (currency, date, rate)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (currency, date) DO UPDATE SET rate = EXCLUDED.rate`,
+			rate.Currency, rate.Date, rate.Rate)
+	}
+
+	err := r.Pool.SendBatch(ctx, batch).Close()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(rates), nil
+}
+
+// GetEffective returns the latest rate of currency dated on or before at.
+func (r *FXRateRepo) GetEffective(ctx context.Context, currency string, at time.Time) (*entity.FXRate, error) {
+	const op = "FXRateRepo.GetEffective"
+	const query = `SELECT currency, date, rate
+                   FROM fx_rates
+                   WHERE currency = $1 AND date <= $2::date
+                   ORDER BY date DESC
+                   LIMIT 1`
+
+	var rate entity.FXRate
+	err := r.Pool.QueryRow(ctx, query, currency, at.UTC()).Scan(
+		&rate.Currency,
+		&rate.Date,
+		&rate.Rate,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, common.ErrFXRateNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &rate, nil
+}
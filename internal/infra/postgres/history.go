@@ -12,6 +12,8 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+const histDefaultSliceCap = 256
+
 type HistoryRepo struct {
 	*postgres.Postgres
 }
@@ -20,14 +22,21 @@ func NewHistoryRepository(pg *postgres.Postgres) *HistoryRepo {
 	return &HistoryRepo{pg}
 }
 
+// Create stores the sample unless one already exists for the same
+// cryptocurrency and timestamp, in which case common.ErrHistoryAlreadyExists
+// is returned.
 func (r *HistoryRepo) Create(ctx context.Context, history *entity.PriceHistory) (*entity.PriceHistory, error) {
 	const op = "HistoryRepo.Create"
 
 	err := r.Pool.QueryRow(ctx,
 		`INSERT INTO price_history (cryptocurrency_id, price, timestamp)
 		VALUES ($1, $2, $3)
+		ON CONFLICT (cryptocurrency_id, timestamp) DO NOTHING
 		RETURNING id;`, history.CryptocurrencyID, history.Price, history.Timestamp).Scan(&history.ID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, common.ErrHistoryAlreadyExists)
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -77,6 +86,52 @@ func (r *HistoryRepo) GetNearestPrice(ctx context.Context, cryptocurrencyID int,
 	}
 }
 
+// GetNearestPrices returns the samples nearest to each of timestamps, the
+// earlier one on ties like GetNearestPrice, or nil where there is none. All
+// timestamps are looked up in one query.
+func (r *HistoryRepo) GetNearestPrices(ctx context.Context, cryptocurrencyID int, timestamps []time.Time) ([]*entity.PriceHistory, error) {
+	const op = "HistoryRepo.GetNearestPrices"
+	const query = `SELECT t.i, n.id, n.price, n.timestamp
+                   FROM unnest($2::timestamptz[]) WITH ORDINALITY AS t(at, i)
+                   CROSS JOIN LATERAL (
+                       SELECT c.id, c.price, c.timestamp
+                       FROM ((SELECT id, price, timestamp
+                              FROM price_history
+                              WHERE cryptocurrency_id = $1 AND timestamp <= t.at
+                              ORDER BY timestamp DESC
+                              LIMIT 1)
+                             UNION ALL
+                             (SELECT id, price, timestamp
+                              FROM price_history
+                              WHERE cryptocurrency_id = $1 AND timestamp > t.at
+                              ORDER BY timestamp
+                              LIMIT 1)) AS c
+                       ORDER BY abs(extract(epoch FROM c.timestamp - t.at)), c.timestamp
+                       LIMIT 1
+                   ) AS n`
+
+	rows, err := r.Pool.Query(ctx, query, cryptocurrencyID, timestamps)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	histories := make([]*entity.PriceHistory, len(timestamps))
+	for rows.Next() {
+		var i int
+		history := &entity.PriceHistory{CryptocurrencyID: cryptocurrencyID}
+		if err := rows.Scan(&i, &history.ID, &history.Price, &history.Timestamp); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		histories[i-1] = history
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return histories, nil
+}
+
 func (r *HistoryRepo) getExactPrice(ctx context.Context, cryptocurrencyID int, timestamp time.Time) (*entity.PriceHistory, error) {
 	const op = "HistoryRepo.getExactPrice"
 
@@ -147,3 +202,192 @@ func (r *HistoryRepo) getNearestAfter(ctx context.Context, cryptocurrencyID int,
 	}
 	return &history, nil
 }
+
+func (r *HistoryRepo) GetStats(ctx context.Context, cryptocurrencyID int, from, to time.Time) (*entity.PriceStats, error) {
+	const op = "HistoryRepo.GetStats"
+	const query = `WITH w AS (
+                       SELECT price, timestamp
+                       FROM price_history
+                       WHERE cryptocurrency_id = $1 AND timestamp >= $2 AND timestamp <= $3
+                   ),
+                   f AS (SELECT price, timestamp FROM w ORDER BY timestamp ASC LIMIT 1),
+                   l AS (SELECT price, timestamp FROM w ORDER BY timestamp DESC LIMIT 1),
+                   lo AS (SELECT price, timestamp FROM w ORDER BY price ASC, timestamp ASC LIMIT 1),
+                   hi AS (SELECT price, timestamp FROM w ORDER BY price DESC, timestamp ASC LIMIT 1),
+                   agg AS (
+                       SELECT count(*) AS cnt,
+                              round(avg(price), 8) AS avg,
+                              round(coalesce(stddev_samp(price), 0), 8) AS stddev
+                       FROM w
+                   )
+                   SELECT agg.cnt, f.price, f.timestamp, l.price, l.timestamp,
+                          lo.price, lo.timestamp, hi.price, hi.timestamp,
+                          agg.avg, agg.stddev,
+                          l.price - f.price,
+                          CASE WHEN f.price = 0 THEN 0
+                               ELSE round((l.price - f.price) / f.price * 100, 4) END
+                   FROM agg, f, l, lo, hi`
+
+	var stats entity.PriceStats
+	err := r.Pool.QueryRow(ctx, query, cryptocurrencyID, from, to).Scan(
+		&stats.Count,
+		&stats.First, &stats.FirstAt,
+		&stats.Last, &stats.LastAt,
+		&stats.Min, &stats.MinAt,
+		&stats.Max, &stats.MaxAt,
+		&stats.Avg,
+		&stats.StdDev,
+		&stats.Change,
+		&stats.ChangePercent,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, common.ErrHistoryNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &stats, nil
+}
+
+// GetCandles aggregates samples into OHLC candles of the given interval aligned to the Unix epoch.
+func (r *HistoryRepo) GetCandles(ctx context.Context, cryptocurrencyID int, interval time.Duration, from, to time.Time) ([]entity.Candle, error) {
+	const op = "HistoryRepo.GetCandles"
+	const query = `SELECT date_bin($2::bigint * interval '1 second', timestamp, 'epoch'::timestamptz) AS bucket,
+                          (array_agg(price ORDER BY timestamp ASC))[1],
+                          max(price),
+                          min(price),
+                          (array_agg(price ORDER BY timestamp DESC))[1],
+                          count(*)
+                   FROM price_history
+                   WHERE cryptocurrency_id = $1 AND timestamp >= $3 AND timestamp <= $4
+                   GROUP BY bucket
+                   ORDER BY bucket`
+
+	rows, err := r.Pool.Query(ctx, query, cryptocurrencyID, int64(interval/time.Second), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	candles := make([]entity.Candle, 0, histDefaultSliceCap)
+	for rows.Next() {
+		var c entity.Candle
+
+		err := rows.Scan(
+			&c.Timestamp, &c.Open, &c.High, &c.Low, &c.Close, &c.Count,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		candles = append(candles, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return candles, nil
+}
+
+// Export streams samples of the cryptocurrencies in [from, to] ordered by
+// cryptocurrency and time to fn without buffering them. Streaming stops
+// with fn's error.
+func (r *HistoryRepo) Export(
+	ctx context.Context,
+	cryptocurrencyIDs []int,
+	from, to time.Time,
+	limit int64,
+	fn func(history *entity.PriceHistory) error,
+) error {
+	const op = "HistoryRepo.Export"
+	const query = `SELECT id, cryptocurrency_id, price, timestamp
+                   FROM price_history
+                   WHERE cryptocurrency_id = ANY($1) AND timestamp >= $2 AND timestamp <= $3
+                   ORDER BY cryptocurrency_id, timestamp
+                   LIMIT $4`
+
+	rows, err := r.Pool.Query(ctx, query, cryptocurrencyIDs, from, to, limit)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var history entity.PriceHistory
+	for rows.Next() {
+		err := rows.Scan(
+			&history.ID,
+			&history.CryptocurrencyID,
+			&history.Price,
+			&history.Timestamp,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := fn(&history); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Upsert loads samples with COPY into a temporary table and merges them into
+// price_history, updating the price of samples already stored for the same
+// cryptocurrency and timestamp. Samples matching a stored one are skipped and
+// counted neither as inserted nor as updated.
+func (r *HistoryRepo) Upsert(ctx context.Context, histories []entity.PriceHistory) (inserted, updated int64, err error) {
+	const op = "HistoryRepo.Upsert"
+
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`CREATE TEMP TABLE price_history_import (
+			cryptocurrency_id INT NOT NULL,
+			price NUMERIC(20, 8) NOT NULL,
+			timestamp TIMESTAMPTZ NOT NULL
+		) ON COMMIT DROP`)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"price_history_import"},
+		[]string{"cryptocurrency_id", "price", "timestamp"},
+		pgx.CopyFromSlice(len(histories), func(i int) ([]any, error) {
+			h := histories[i]
+			return []any{h.CryptocurrencyID, h.Price, h.Timestamp}, nil
+		}))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// xmax is zero only for freshly inserted rows.
+	err = tx.QueryRow(ctx,
+		`WITH upserted AS (
+			INSERT INTO price_history (cryptocurrency_id, price, timestamp)
+			SELECT cryptocurrency_id, price, timestamp FROM price_history_import
+			ON CONFLICT (cryptocurrency_id, timestamp) DO UPDATE SET price = EXCLUDED.price
+			WHERE price_history.price <> EXCLUDED.price
+			RETURNING xmax = 0 AS inserted
+		)
+		SELECT count(*) FILTER (WHERE inserted), count(*) FILTER (WHERE NOT inserted)
+		FROM upserted`).Scan(&inserted, &updated)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return inserted, updated, nil
+}
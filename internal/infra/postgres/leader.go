@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	RoleLeader     = "leader"
+	RoleFollower   = "follower"
+	RoleStandalone = "standalone"
+)
+
+// LeaderElector elects one leader among replicas by holding a session level
+// advisory lock on a dedicated connection. Followers retry the lock every
+// interval, so leadership moves within an interval once the leader's session
+// ends. When disabled every replica acts as a standalone leader.
+type LeaderElector struct {
+	*postgres.Postgres
+	log      *slog.Logger
+	enabled  bool
+	key      int64
+	interval time.Duration
+	leader   atomic.Bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewLeaderElector(
+	pg *postgres.Postgres,
+	log *slog.Logger,
+	enabled bool,
+	key int64,
+	interval time.Duration,
+) *LeaderElector {
+	return &LeaderElector{
+		Postgres: pg,
+		log:      log,
+		enabled:  enabled,
+		key:      key,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+func (e *LeaderElector) IsLeader() bool {
+	return !e.enabled || e.leader.Load()
+}
+
+func (e *LeaderElector) Role() string {
+	switch {
+	case !e.enabled:
+		return RoleStandalone
+	case e.leader.Load():
+		return RoleLeader
+	default:
+		return RoleFollower
+	}
+}
+
+func (e *LeaderElector) Start() {
+	if !e.enabled {
+		return
+	}
+	e.log.Info("Start leader election")
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		var conn *pgxpool.Conn
+		for {
+			if conn == nil {
+				conn = e.acquire()
+			} else if !e.alive(conn) {
+				conn = nil
+			}
+
+			select {
+			case <-ticker.C:
+			case <-e.done:
+				e.release(conn)
+				return
+			}
+		}
+	}()
+}
+
+// acquire returns the connection holding the lock, or nil.
+func (e *LeaderElector) acquire() *pgxpool.Conn {
+	const op = "LeaderElector.acquire"
+	log := e.log.With(slog.String("op", op))
+
+	ctx, done := context.WithTimeout(context.Background(), e.interval)
+	defer done()
+
+	conn, err := e.Pool.Acquire(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to acquire connection! Error: %s", err))
+		return nil
+	}
+
+	var locked bool
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, e.key).Scan(&locked)
+	if err != nil || !locked {
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to take leader lock! Error: %s", err))
+		}
+		conn.Release()
+		return nil
+	}
+
+	e.leader.Store(true)
+	log.Info("Became leader")
+	return conn
+}
+
+// alive checks the session holding the lock and gives up leadership if it is lost.
+func (e *LeaderElector) alive(conn *pgxpool.Conn) bool {
+	const op = "LeaderElector.alive"
+	log := e.log.With(slog.String("op", op))
+
+	ctx, done := context.WithTimeout(context.Background(), e.interval)
+	defer done()
+
+	if err := conn.Ping(ctx); err != nil {
+		e.leader.Store(false)
+		log.Error(fmt.Sprintf("Lost leadership! Error: %s", err))
+
+		// The session may still hold the lock, so it must not return to the pool.
+		_ = conn.Hijack().Close(context.Background())
+		return false
+	}
+
+	return true
+}
+
+func (e *LeaderElector) release(conn *pgxpool.Conn) {
+	if conn == nil {
+		return
+	}
+	e.leader.Store(false)
+
+	ctx, done := context.WithTimeout(context.Background(), e.interval)
+	defer done()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, e.key); err != nil {
+		_ = conn.Hijack().Close(ctx)
+		return
+	}
+	conn.Release()
+}
+
+func (e *LeaderElector) Stop() {
+	if !e.enabled {
+		return
+	}
+	e.log.Info("Stop leader election")
+	close(e.done)
+	e.wg.Wait()
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+)
+
+const listenerRetryDelay = time.Second
+
+// TrackingSubscriber keeps a set of tracked coins.
+type TrackingSubscriber interface {
+	AddCoin(c entity.TrackedCoin)
+	RemoveCoin(c entity.Cryptocurrency)
+	Resync(ctx context.Context) error
+}
+
+// TrackingListener applies tracking changes published by any replica to the
+// subscriber. Changes missed while disconnected are caught up by a full
+// resync on every connect and every resyncInterval.
+type TrackingListener struct {
+	*postgres.Postgres
+	log            *slog.Logger
+	sub            TrackingSubscriber
+	resyncInterval time.Duration
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+}
+
+func NewTrackingListener(
+	pg *postgres.Postgres,
+	log *slog.Logger,
+	sub TrackingSubscriber,
+	resyncInterval time.Duration,
+) *TrackingListener {
+	return &TrackingListener{
+		Postgres:       pg,
+		log:            log,
+		sub:            sub,
+		resyncInterval: resyncInterval,
+	}
+}
+
+func (l *TrackingListener) Start() {
+	l.log.Info("Start listening tracking changes")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	l.wg.Add(2)
+	go func() {
+		defer l.wg.Done()
+
+		for {
+			err := l.listen(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			l.log.Error(fmt.Sprintf("Error listening tracking changes: %v", err))
+
+			select {
+			case <-time.After(listenerRetryDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer l.wg.Done()
+
+		ticker := time.NewTicker(l.resyncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.resync(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (l *TrackingListener) listen(ctx context.Context) error {
+	const op = "TrackingListener.listen"
+
+	conn, err := l.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	// A listening session must not return to the pool.
+	pc := conn.Hijack()
+	defer pc.Close(context.Background())
+
+	if _, err := pc.Exec(ctx, "LISTEN "+trackingChannel); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	l.resync(ctx)
+
+	for {
+		n, err := pc.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		var change trackingChange
+		if err := json.Unmarshal([]byte(n.Payload), &change); err != nil {
+			l.log.Error(fmt.Sprintf("Error decoding tracking change %q: %v", n.Payload, err))
+			continue
+		}
+
+		cr := entity.Cryptocurrency{ID: change.ID, Symbol: change.Symbol}
+		if change.Active {
+			l.sub.AddCoin(entity.TrackedCoin{
+				Cryptocurrency: cr,
+				PollInterval:   time.Duration(change.IntervalMS) * time.Millisecond,
+				Priority:       change.Priority,
+			})
+		} else {
+			l.sub.RemoveCoin(cr)
+		}
+	}
+}
+
+func (l *TrackingListener) resync(ctx context.Context) {
+	ctx, done := context.WithTimeout(ctx, 5*time.Second)
+	defer done()
+
+	if err := l.sub.Resync(ctx); err != nil {
+		l.log.Error(fmt.Sprintf("Error resyncing tracked coins: %v", err))
+	}
+}
+
+func (l *TrackingListener) Stop() {
+	l.log.Info("Stop listening tracking changes")
+	l.cancel()
+	l.wg.Wait()
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+// serveNotifications accepts connections on l and sends payloads to every
+// connection listening to trackingChannel until it receives from drop or done
+// is closed.
+func serveNotifications(l net.Listener, payloads <-chan string, drop, done <-chan struct{}) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+
+			backend := pgproto3.NewBackend(conn, conn)
+			if _, err := backend.ReceiveStartupMessage(); err != nil {
+				return
+			}
+			backend.Send(&pgproto3.AuthenticationOk{})
+			backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+			backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+			backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+
+			for {
+				msg, err := backend.Receive()
+				if err != nil {
+					return
+				}
+				q, ok := msg.(*pgproto3.Query)
+				if !ok {
+					continue
+				}
+				if !strings.HasPrefix(q.String, "LISTEN ") {
+					backend.Send(&pgproto3.RowDescription{})
+					backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")})
+					backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+					if err := backend.Flush(); err != nil {
+						return
+					}
+					continue
+				}
+
+				backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("LISTEN")})
+				backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+				if err := backend.Flush(); err != nil {
+					return
+				}
+				for {
+					select {
+					case p := <-payloads:
+						backend.Send(&pgproto3.NotificationResponse{PID: 1, Channel: trackingChannel, Payload: p})
+						if err := backend.Flush(); err != nil {
+							return
+						}
+					case <-drop:
+						return
+					case <-done:
+						return
+					}
+				}
+			}
+		}()
+	}
+}
+
+type fakeSubscriber struct {
+	events chan string
+}
+
+func (s *fakeSubscriber) AddCoin(c entity.TrackedCoin) {
+	s.events <- fmt.Sprintf("add %s %s %d", c.Symbol, c.PollInterval, c.Priority)
+}
+
+func (s *fakeSubscriber) RemoveCoin(c entity.Cryptocurrency) {
+	s.events <- "remove " + c.Symbol
+}
+
+func (s *fakeSubscriber) Resync(context.Context) error {
+	s.events <- "resync"
+	return nil
+}
+
+func (s *fakeSubscriber) expect(t *testing.T, want string) {
+	t.Helper()
+
+	select {
+	case got := <-s.events:
+		if got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %q", want)
+	}
+}
+
+func TestTrackingListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	payloads := make(chan string)
+	drop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go serveNotifications(l, payloads, drop, done)
+
+	pg, err := postgres.New("postgres://user@"+l.Addr().String()+"/db?sslmode=disable&default_query_exec_mode=simple_protocol",
+		postgres.ConnAttempts(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pg.Close()
+
+	sub := &fakeSubscriber{events: make(chan string, 1)}
+	lst := NewTrackingListener(pg, slog.New(slog.NewTextHandler(io.Discard, nil)), sub, time.Hour)
+	lst.Start()
+	defer lst.Stop()
+
+	// Changes published before listening are caught up by a resync.
+	sub.expect(t, "resync")
+
+	payloads <- `{"id":1,"symbol":"BTC","active":true,"interval_ms":1500,"priority":2}`
+	sub.expect(t, "add BTC 1.5s 2")
+
+	payloads <- `{"id":2,"symbol":"ETH","active":true,"interval_ms":0,"priority":0}`
+	sub.expect(t, "add ETH 0s 0")
+
+	// Malformed changes are skipped.
+	payloads <- `{"id":`
+	payloads <- `{"id":1,"symbol":"BTC","active":false}`
+	sub.expect(t, "remove BTC")
+
+	// Changes missed while disconnected are caught up on reconnect.
+	drop <- struct{}{}
+	sub.expect(t, "resync")
+}
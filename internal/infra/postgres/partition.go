@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// partitionLayout names monthly partitions of price_history. Partitions not
+// matching it, as price_history_legacy made of the table existing before
+// partitioning, are left alone.
+const partitionLayout = "price_history_2006_01"
+
+type PartitionRepo struct {
+	*postgres.Postgres
+}
+
+func NewPartitionRepository(pg *postgres.Postgres) *PartitionRepo {
+	return &PartitionRepo{pg}
+}
+
+// GetMonths returns the first days of months having a price_history partition.
+func (r *PartitionRepo) GetMonths(ctx context.Context) ([]time.Time, error) {
+	const op = "PartitionRepo.GetMonths"
+	const query = `SELECT c.relname
+                   FROM pg_inherits AS i
+                   JOIN pg_class AS c ON c.oid = i.inhrelid
+                   WHERE i.inhparent = 'price_history'::regclass
+                   ORDER BY c.relname`
+
+	rows, err := r.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	months := make([]time.Time, 0, len(names))
+	for _, name := range names {
+		month, err := time.Parse(partitionLayout, name)
+		if err != nil {
+			continue
+		}
+		months = append(months, month)
+	}
+
+	return months, nil
+}
+
+// Create creates the partition of the month starting at month.
+func (r *PartitionRepo) Create(ctx context.Context, month time.Time) error {
+	const op = "PartitionRepo.Create"
+
+	name := pgx.Identifier{month.Format(partitionLayout)}.Sanitize()
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF price_history FOR VALUES FROM ('%s') TO ('%s')`,
+		name, month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339))
+
+	if _, err := r.Pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Detach detaches the partition of the month without blocking writes to
+// other partitions and drops it unless keep is set.
+func (r *PartitionRepo) Detach(ctx context.Context, month time.Time, keep bool) error {
+	const op = "PartitionRepo.Detach"
+
+	name := pgx.Identifier{month.Format(partitionLayout)}.Sanitize()
+	if _, err := r.Pool.Exec(ctx, fmt.Sprintf(`ALTER TABLE price_history DETACH PARTITION %s CONCURRENTLY`, name)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if keep {
+		return nil
+	}
+
+	if _, err := r.Pool.Exec(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, name)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+const tradeDefaultSliceCap = 50
+
+type PortfolioRepo struct {
+	*postgres.Postgres
+}
+
+func NewPortfolioRepository(pg *postgres.Postgres) *PortfolioRepo {
+	return &PortfolioRepo{pg}
+}
+
+func (r *PortfolioRepo) Create(ctx context.Context, p *entity.Portfolio) (*entity.Portfolio, error) {
+	const op = "PortfolioRepo.Create"
+
+	err := r.Pool.QueryRow(ctx,
+		`INSERT INTO portfolios (name, accounting)
+		VALUES ($1, $2)
+		RETURNING id, created_at;`, p.Name, p.Accounting).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "SQLSTATE 23505") {
+			return nil, fmt.Errorf("%s: %w", op, common.ErrPortfolioAlreadyExists)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return p, nil
+}
+
+func (r *PortfolioRepo) GetByID(ctx context.Context, id int) (*entity.Portfolio, error) {
+	const op = "PortfolioRepo.GetByID"
+
+	var p entity.Portfolio
+	err := conn(ctx, r.Postgres).QueryRow(ctx,
+		`SELECT id, name, accounting, created_at FROM portfolios WHERE id=$1`, id).
+		Scan(&p.ID, &p.Name, &p.Accounting, &p.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, common.ErrPortfolioNotFound)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &p, nil
+}
+
+// Lock locks the portfolio row until the end of the transaction,
+// serializing trades of the portfolio.
+func (r *PortfolioRepo) Lock(ctx context.Context, id int) error {
+	const op = "PortfolioRepo.Lock"
+
+	var locked int
+	err := conn(ctx, r.Postgres).QueryRow(ctx,
+		`SELECT id FROM portfolios WHERE id=$1 FOR UPDATE`, id).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, common.ErrPortfolioNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PortfolioRepo) AddTrade(ctx context.Context, t *entity.Trade) (*entity.Trade, error) {
+	const op = "PortfolioRepo.AddTrade"
+
+	err := conn(ctx, r.Postgres).QueryRow(ctx,
+		`INSERT INTO portfolio_trades (portfolio_id, cryptocurrency_id, quantity, price, timestamp)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id;`, t.PortfolioID, t.CryptocurrencyID, t.Quantity, t.Price, t.Timestamp).Scan(&t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return t, nil
+}
+
+// GetTrades returns trades of the portfolio in execution order.
+func (r *PortfolioRepo) GetTrades(ctx context.Context, portfolioID int) ([]entity.Trade, error) {
+	const op = "PortfolioRepo.GetTrades"
+
+	query := `SELECT t.id, t.portfolio_id, t.cryptocurrency_id, cr.symbol, t.quantity, t.price, t.timestamp
+			FROM portfolio_trades AS t
+			JOIN cryptocurrencies AS cr ON cr.id = t.cryptocurrency_id
+			WHERE t.portfolio_id = $1
+			ORDER BY t.timestamp, t.id`
+
+	rows, err := conn(ctx, r.Postgres).Query(ctx, query, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	trades := make([]entity.Trade, 0, tradeDefaultSliceCap)
+	for rows.Next() {
+		var t entity.Trade
+
+		err := rows.Scan(
+			&t.ID, &t.PortfolioID, &t.CryptocurrencyID, &t.Symbol, &t.Quantity, &t.Price, &t.Timestamp,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		trades = append(trades, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return trades, nil
+}
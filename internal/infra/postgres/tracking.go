@@ -2,9 +2,11 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Homyakadze14/AFFARM_tz/internal/common"
 	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
@@ -12,7 +14,37 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-const trackDefaultSliceCap = 50
+const (
+	trackDefaultSliceCap = 50
+	trackingChannel      = "tracking_changes"
+)
+
+// trackingChange is the payload of notifications on trackingChannel.
+type trackingChange struct {
+	ID         int    `json:"id"`
+	Symbol     string `json:"symbol"`
+	Active     bool   `json:"active"`
+	IntervalMS int64  `json:"interval_ms"` // zero for the default interval
+	Priority   int    `json:"priority"`
+}
+
+// intervalMS returns the poll_interval_ms of the interval, NULL for the
+// default interval so it follows PARSER_INTERVAL.
+func intervalMS(d time.Duration) *int64 {
+	if d <= 0 {
+		return nil
+	}
+	ms := d.Milliseconds()
+	return &ms
+}
+
+// pollInterval is the inverse of intervalMS.
+func pollInterval(ms *int64) time.Duration {
+	if ms == nil {
+		return 0
+	}
+	return time.Duration(*ms) * time.Millisecond
+}
 
 type TrackingRepo struct {
 	*postgres.Postgres
@@ -25,10 +57,10 @@ func NewTrackingRepository(pg *postgres.Postgres) *TrackingRepo {
 func (r *TrackingRepo) Create(ctx context.Context, trc *entity.Tracking) (*entity.Tracking, error) {
 	const op = "TrackingRepo.Create"
 
-	err := r.Pool.QueryRow(ctx,
-		`INSERT INTO trackings (cryptocurrency_id, is_active)
-		VALUES ($1, $2)
-		RETURNING id;`, trc.CryptocurrencyID, trc.IsActive).Scan(&trc.ID)
+	err := conn(ctx, r.Postgres).QueryRow(ctx,
+		`INSERT INTO trackings (cryptocurrency_id, is_active, poll_interval_ms, priority)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;`, trc.CryptocurrencyID, trc.IsActive, intervalMS(trc.PollInterval), trc.Priority).Scan(&trc.ID)
 	if err != nil {
 		if strings.Contains(err.Error(), "SQLSTATE 23505") {
 			return nil, fmt.Errorf("%s: %w", op, common.ErrTrackingAlreadyExists)
@@ -40,18 +72,20 @@ func (r *TrackingRepo) Create(ctx context.Context, trc *entity.Tracking) (*entit
 }
 
 func (r *TrackingRepo) get(ctx context.Context, op string, condition string, args ...interface{}) (*entity.Tracking, error) {
-	row := r.Pool.QueryRow(ctx,
-		fmt.Sprintf("SELECT id, cryptocurrency_id, is_active FROM trackings WHERE %s", condition),
+	row := conn(ctx, r.Postgres).QueryRow(ctx,
+		fmt.Sprintf("SELECT id, cryptocurrency_id, is_active, poll_interval_ms, priority FROM trackings WHERE %s", condition),
 		args...)
 
 	var t entity.Tracking
-	err := row.Scan(&t.ID, &t.CryptocurrencyID, &t.IsActive)
+	var interval *int64
+	err := row.Scan(&t.ID, &t.CryptocurrencyID, &t.IsActive, &interval, &t.Priority)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, common.ErrTrackingNotFound)
 		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	t.PollInterval = pollInterval(interval)
 
 	return &t, nil
 }
@@ -63,12 +97,36 @@ func (r *TrackingRepo) GetByCryptocurrencyID(ctx context.Context, crid int) (*en
 	return r.get(ctx, op, condition, crid)
 }
 
+// Notify publishes the tracking change to all replicas. Within a transaction
+// the notification is delivered on commit only.
+func (r *TrackingRepo) Notify(ctx context.Context, coin *entity.TrackedCoin, active bool) error {
+	const op = "TrackingRepo.Notify"
+
+	payload, err := json.Marshal(trackingChange{
+		ID:         coin.ID,
+		Symbol:     coin.Symbol,
+		Active:     active,
+		IntervalMS: coin.PollInterval.Milliseconds(),
+		Priority:   coin.Priority,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = conn(ctx, r.Postgres).Exec(ctx, `SELECT pg_notify($1, $2)`, trackingChannel, string(payload))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
 func (r *TrackingRepo) Update(ctx context.Context, trc *entity.Tracking) (*entity.Tracking, error) {
 	const op = "TrackingRepo.Update"
 
-	_, err := r.Pool.Exec(ctx,
-		`UPDATE trackings SET is_active=$1 WHERE id=$2`,
-		trc.IsActive, trc.ID)
+	_, err := conn(ctx, r.Postgres).Exec(ctx,
+		`UPDATE trackings SET is_active=$1, poll_interval_ms=$2, priority=$3 WHERE id=$4`,
+		trc.IsActive, intervalMS(trc.PollInterval), trc.Priority, trc.ID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
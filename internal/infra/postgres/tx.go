@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Homyakadze14/AFFARM_tz/pkg/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type txKey struct{}
+
+// querier is implemented by both the pool and a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// conn returns the transaction started by TxManager.Do for ctx, or the pool.
+func conn(ctx context.Context, pg *postgres.Postgres) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pg.Pool
+}
+
+// TxManager runs repository calls in one transaction.
+type TxManager struct {
+	*postgres.Postgres
+}
+
+func NewTxManager(pg *postgres.Postgres) *TxManager {
+	return &TxManager{pg}
+}
+
+// Do runs fn in a transaction carried by the context passed to it. The
+// transaction is committed when fn succeeds and rolled back otherwise.
+// Nested calls join the outer transaction.
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "TxManager.Do"
+
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// statsAccumulator computes price stats like HistoryRepo.GetStats over
+// samples added in time order.
+type statsAccumulator struct {
+	stats entity.PriceStats
+	sum   decimal.Decimal
+	sumSq decimal.Decimal
+}
+
+func (a *statsAccumulator) add(h *entity.PriceHistory) {
+	s := &a.stats
+	if s.Count == 0 {
+		s.First, s.FirstAt = h.Price, h.Timestamp
+		s.Min, s.MinAt = h.Price, h.Timestamp
+		s.Max, s.MaxAt = h.Price, h.Timestamp
+	}
+	// Strict comparisons keep the earliest sample of equal extremes.
+	if h.Price.LessThan(s.Min) {
+		s.Min, s.MinAt = h.Price, h.Timestamp
+	}
+	if h.Price.GreaterThan(s.Max) {
+		s.Max, s.MaxAt = h.Price, h.Timestamp
+	}
+	s.Last, s.LastAt = h.Price, h.Timestamp
+	s.Count++
+
+	a.sum = a.sum.Add(h.Price)
+	a.sumSq = a.sumSq.Add(h.Price.Mul(h.Price))
+}
+
+// result returns the stats, or false when no sample was added.
+func (a *statsAccumulator) result() (*entity.PriceStats, bool) {
+	s := a.stats
+	if s.Count == 0 {
+		return nil, false
+	}
+
+	n := decimal.NewFromInt(s.Count)
+	s.Avg = a.sum.DivRound(n, 8)
+	s.StdDev = decimal.Zero
+	if s.Count > 1 {
+		// Sample variance as (n*Σx² - (Σx)²) / (n*(n-1)), exact like
+		// stddev_samp on NUMERIC, rounded once after the square root.
+		num := n.Mul(a.sumSq).Sub(a.sum.Mul(a.sum))
+		s.StdDev = sqrtRound(num, n.Mul(n.Sub(decimal.NewFromInt(1))), 8)
+	}
+	s.Change = s.Last.Sub(s.First)
+	s.ChangePercent = decimal.Zero
+	if !s.First.IsZero() {
+		s.ChangePercent = s.Change.Mul(hundred).DivRound(s.First, 4)
+	}
+
+	return &s, true
+}
+
+// sqrtRound returns the square root of num/den rounded half up to places
+// decimal places. It is computed on integers, so the rounding is exact.
+func sqrtRound(num, den decimal.Decimal, places int32) decimal.Decimal {
+	if !num.IsPositive() || !den.IsPositive() {
+		return decimal.Zero
+	}
+
+	// q4 = 4 * num * 10^(2*places) / den, truncated.
+	n, d := num.Coefficient(), den.Coefficient()
+	n.Mul(n, big.NewInt(4))
+	if exp := int64(num.Exponent()) - int64(den.Exponent()) + 2*int64(places); exp >= 0 {
+		n.Mul(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	} else {
+		d.Mul(d, new(big.Int).Exp(big.NewInt(10), big.NewInt(-exp), nil))
+	}
+	q4 := n.Quo(n, d)
+
+	// round(sqrt(q)) = floor((sqrt(4q) + 1) / 2) = (isqrt(4q) + 1) / 2.
+	root := q4.Sqrt(q4)
+	root.Add(root, big.NewInt(1))
+	root.Rsh(root, 1)
+
+	return decimal.NewFromBigInt(root, -places)
+}
+
+// candleAccumulator builds candles like HistoryRepo.GetCandles, aligned to
+// the Unix epoch, over samples added in time order.
+type candleAccumulator struct {
+	interval int64
+	candles  []entity.Candle
+}
+
+func newCandleAccumulator(interval time.Duration) *candleAccumulator {
+	return &candleAccumulator{interval: int64(interval / time.Second)}
+}
+
+func (a *candleAccumulator) add(h *entity.PriceHistory) {
+	sec := h.Timestamp.Unix()
+	bucket := time.Unix(sec-((sec%a.interval)+a.interval)%a.interval, 0)
+
+	if n := len(a.candles); n > 0 && a.candles[n-1].Timestamp.Equal(bucket) {
+		c := &a.candles[n-1]
+		c.High = decimal.Max(c.High, h.Price)
+		c.Low = decimal.Min(c.Low, h.Price)
+		c.Close = h.Price
+		c.Count++
+		return
+	}
+
+	a.candles = append(a.candles, entity.Candle{
+		Timestamp: bucket,
+		Open:      h.Price,
+		High:      h.Price,
+		Low:       h.Price,
+		Close:     h.Price,
+		Count:     1,
+	})
+}
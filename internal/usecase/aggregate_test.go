@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+func TestSqrtRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		num, den string
+		places   int32
+		want     string
+	}{
+		{name: "irrational", num: "2", den: "1", places: 8, want: "1.41421356"},
+		{name: "exact", num: "1", den: "4", places: 8, want: "0.5"},
+		{name: "half rounds up", num: "0.0225", den: "1", places: 1, want: "0.2"},
+		{name: "fraction", num: "32", den: "7", places: 8, want: "2.13808994"},
+		{name: "beyond float precision", num: "1", den: "3", places: 20, want: "0.57735026918962576451"},
+		{name: "zero", num: "0", den: "5", places: 8, want: "0"},
+		{name: "negative", num: "-1", den: "5", places: 8, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sqrtRound(decimal.RequireFromString(tt.num), decimal.RequireFromString(tt.den), tt.places)
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsAccumulator(t *testing.T) {
+	var acc statsAccumulator
+	if _, ok := acc.result(); ok {
+		t.Fatal("expected no stats without samples")
+	}
+
+	start := time.Unix(1754578944, 0)
+	for i, p := range []string{"2", "4", "4", "4", "5", "5", "7", "9"} {
+		acc.add(&entity.PriceHistory{Price: decimal.RequireFromString(p), Timestamp: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	stats, ok := acc.result()
+	if !ok {
+		t.Fatal("expected stats")
+	}
+	for _, f := range []struct {
+		name string
+		got  decimal.Decimal
+		want string
+	}{
+		// Sample deviation of the series is sqrt(32/7).
+		{name: "stddev", got: stats.StdDev, want: "2.13808994"},
+		{name: "avg", got: stats.Avg, want: "5"},
+		{name: "min", got: stats.Min, want: "2"},
+		{name: "max", got: stats.Max, want: "9"},
+		{name: "change", got: stats.Change, want: "7"},
+		{name: "change percent", got: stats.ChangePercent, want: "350"},
+	} {
+		if !f.got.Equal(decimal.RequireFromString(f.want)) {
+			t.Errorf("%s = %s, want %s", f.name, f.got, f.want)
+		}
+	}
+	if stats.Count != 8 || !stats.MinAt.Equal(start) || !stats.MaxAt.Equal(start.Add(7*time.Minute)) {
+		t.Errorf("got count %d, min at %s, max at %s", stats.Count, stats.MinAt, stats.MaxAt)
+	}
+}
@@ -0,0 +1,424 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+)
+
+type ArchiveStorage interface {
+	Pending(ctx context.Context, before time.Time) ([]entity.Archive, error)
+	Commit(ctx context.Context, a *entity.Archive) error
+	GetOverlapping(ctx context.Context, cryptocurrencyID int, from, to time.Time) ([]entity.Archive, error)
+	GetAround(ctx context.Context, cryptocurrencyID int, timestamp time.Time) ([]entity.Archive, error)
+}
+
+type ArchiveStore interface {
+	Write(ctx context.Context, key string, histories []entity.PriceHistory) error
+	Read(ctx context.Context, key string) ([]entity.PriceHistory, error)
+}
+
+// errArchiveLimit stops merging of export rows once the limit is reached.
+var errArchiveLimit = errors.New("archive export limit reached")
+
+// ArchiveService moves aged price history to object storage. It wraps
+// HistoryStorage so that nearest price lookups, stats, candles and exports
+// also read archived samples. Decoded archives are cached up to cacheRows
+// samples.
+type ArchiveService struct {
+	HistoryStorage
+	log   *slog.Logger
+	ast   ArchiveStorage
+	store ArchiveStore
+	after time.Duration
+	cache *archiveCache
+}
+
+func NewArchiveService(
+	log *slog.Logger,
+	hst HistoryStorage,
+	ast ArchiveStorage,
+	store ArchiveStore,
+	after time.Duration,
+	cacheRows int,
+) *ArchiveService {
+	return &ArchiveService{
+		HistoryStorage: hst,
+		log:            log,
+		ast:            ast,
+		store:          store,
+		after:          after,
+		cache:          newArchiveCache(cacheRows),
+	}
+}
+
+// Archive writes each month of a cryptocurrency history that ended before
+// the retention window to a Parquet object and deletes archived samples.
+// It returns the number of archived months.
+func (s *ArchiveService) Archive(ctx context.Context) (int, error) {
+	const op = "ArchiveService.Archive"
+	cutoff := time.Now().UTC().Add(-s.after)
+	before := time.Date(cutoff.Year(), cutoff.Month(), 1, 0, 0, 0, 0, time.UTC)
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Time("before", before))
+
+	log.Debug("trying to get pending archives")
+	pending, err := s.ast.Pending(ctx, before)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get pending archives! Error: %s", err))
+		return 0, err
+	}
+
+	for i := range pending {
+		if err := s.archive(ctx, &pending[i]); err != nil {
+			return i, err
+		}
+	}
+	if len(pending) > 0 {
+		log.Info(fmt.Sprintf("archived %d months of price history", len(pending)))
+	}
+
+	return len(pending), nil
+}
+
+func (s *ArchiveService) archive(ctx context.Context, a *entity.Archive) error {
+	const op = "ArchiveService.archive"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Int("cryptocurrency_id", a.CryptocurrencyID),
+		slog.Time("period_start", a.PeriodStart))
+
+	log.Debug("trying to read price history")
+	histories := make([]entity.PriceHistory, 0, a.Rows)
+	err := s.HistoryStorage.Export(ctx, []int{a.CryptocurrencyID}, a.PeriodStart, a.PeriodEnd.Add(-time.Microsecond),
+		math.MaxInt64, func(h *entity.PriceHistory) error {
+			histories = append(histories, *h)
+			return nil
+		})
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to read price history! Error: %s", err))
+		return err
+	}
+
+	a.Rows = int64(len(histories))
+	a.Key = fmt.Sprintf("price_history/cryptocurrency_id=%d/%s.parquet", a.CryptocurrencyID, a.PeriodStart.Format("2006-01"))
+
+	log.Debug("trying to write archive", "key", a.Key)
+	if err := s.store.Write(ctx, a.Key, histories); err != nil {
+		log.Error(fmt.Sprintf("fail to write archive! Error: %s", err))
+		return err
+	}
+
+	log.Debug("trying to commit archive")
+	if err := s.ast.Commit(ctx, a); err != nil {
+		log.Error(fmt.Sprintf("fail to commit archive! Error: %s", err))
+		return err
+	}
+	log.Debug(fmt.Sprintf("successfully archived %d rows", a.Rows))
+
+	return nil
+}
+
+// GetNearestPrice returns the sample nearest to timestamp among stored and
+// archived ones. Archives are read only when their period is closer to
+// timestamp than the nearest stored sample, so lookups of recent prices never
+// touch object storage.
+func (s *ArchiveService) GetNearestPrice(ctx context.Context, cryptocurrencyID int, timestamp time.Time) (*entity.PriceHistory, error) {
+	const op = "ArchiveService.GetNearestPrice"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Int("cryptocurrency_id", cryptocurrencyID),
+		slog.Time("timestamp", timestamp))
+
+	best, err := s.HistoryStorage.GetNearestPrice(ctx, cryptocurrencyID, timestamp)
+	if err != nil && !errors.Is(err, common.ErrHistoryNotFound) {
+		return nil, err
+	}
+
+	archives, aerr := s.ast.GetAround(ctx, cryptocurrencyID, timestamp)
+	if aerr != nil {
+		log.Error(fmt.Sprintf("fail to get archives! Error: %s", aerr))
+		return nil, aerr
+	}
+
+	best, err = s.nearestArchived(ctx, log, archives, best, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if best == nil {
+		return nil, common.ErrHistoryNotFound
+	}
+
+	return best, nil
+}
+
+// GetNearestPrices returns the stored or archived samples nearest to each of
+// timestamps, ordered in time, or nil where there is none. Archives around
+// and between the timestamps are looked up once.
+func (s *ArchiveService) GetNearestPrices(ctx context.Context, cryptocurrencyID int, timestamps []time.Time) ([]*entity.PriceHistory, error) {
+	const op = "ArchiveService.GetNearestPrices"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Int("cryptocurrency_id", cryptocurrencyID))
+
+	best, err := s.HistoryStorage.GetNearestPrices(ctx, cryptocurrencyID, timestamps)
+	if err != nil || len(timestamps) == 0 {
+		return best, err
+	}
+
+	first, last := timestamps[0], timestamps[len(timestamps)-1]
+	archives, err := s.ast.GetOverlapping(ctx, cryptocurrencyID, first, last)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get archives! Error: %s", err))
+		return nil, err
+	}
+	for _, at := range []time.Time{first, last} {
+		around, err := s.ast.GetAround(ctx, cryptocurrencyID, at)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get archives! Error: %s", err))
+			return nil, err
+		}
+		archives = append(archives, around...)
+	}
+	slices.SortFunc(archives, func(a, b entity.Archive) int { return a.ID - b.ID })
+	archives = slices.CompactFunc(archives, func(a, b entity.Archive) bool { return a.ID == b.ID })
+
+	for i, at := range timestamps {
+		best[i], err = s.nearestArchived(ctx, log, archives, best[i], at)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return best, nil
+}
+
+// nearestArchived returns the sample of archives nearer to timestamp than
+// best, or best. Archives farther than best are not read.
+func (s *ArchiveService) nearestArchived(
+	ctx context.Context,
+	log *slog.Logger,
+	archives []entity.Archive,
+	best *entity.PriceHistory,
+	timestamp time.Time,
+) (*entity.PriceHistory, error) {
+	for _, a := range archives {
+		if best != nil && distance(a, timestamp) >= best.Timestamp.Sub(timestamp).Abs() {
+			continue
+		}
+
+		histories, err := s.read(ctx, a)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to read archive %s! Error: %s", a.Key, err))
+			return nil, err
+		}
+
+		// Candidates are copied, callers may change the returned sample.
+		i := sort.Search(len(histories), func(i int) bool {
+			return histories[i].Timestamp.After(timestamp)
+		})
+		if i > 0 {
+			h := histories[i-1]
+			best = nearest(best, &h, timestamp)
+		}
+		if i < len(histories) {
+			h := histories[i]
+			best = nearest(best, &h, timestamp)
+		}
+	}
+
+	return best, nil
+}
+
+// distance returns how far timestamp is from the period of the archive.
+func distance(a entity.Archive, timestamp time.Time) time.Duration {
+	switch {
+	case timestamp.Before(a.PeriodStart):
+		return a.PeriodStart.Sub(timestamp)
+	case !timestamp.Before(a.PeriodEnd):
+		return timestamp.Sub(a.PeriodEnd)
+	default:
+		return 0
+	}
+}
+
+// read returns decoded samples of the archive from the cache or the store.
+func (s *ArchiveService) read(ctx context.Context, a entity.Archive) ([]entity.PriceHistory, error) {
+	if histories, ok := s.cache.get(a.ID); ok {
+		return histories, nil
+	}
+
+	histories, err := s.store.Read(ctx, a.Key)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(a.ID, histories)
+
+	return histories, nil
+}
+
+// GetStats returns price stats of stored and archived samples in [from, to].
+// Windows without archives are aggregated by the database.
+func (s *ArchiveService) GetStats(ctx context.Context, cryptocurrencyID int, from, to time.Time) (*entity.PriceStats, error) {
+	const op = "ArchiveService.GetStats"
+
+	archived, err := s.archived(ctx, cryptocurrencyID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !archived {
+		return s.HistoryStorage.GetStats(ctx, cryptocurrencyID, from, to)
+	}
+
+	var acc statsAccumulator
+	err = s.Export(ctx, []int{cryptocurrencyID}, from, to, math.MaxInt64, func(h *entity.PriceHistory) error {
+		acc.add(h)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats, ok := acc.result()
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, common.ErrHistoryNotFound)
+	}
+	return stats, nil
+}
+
+// GetCandles returns candles of stored and archived samples in [from, to].
+// Windows without archives are aggregated by the database.
+func (s *ArchiveService) GetCandles(ctx context.Context, cryptocurrencyID int, interval time.Duration, from, to time.Time) ([]entity.Candle, error) {
+	const op = "ArchiveService.GetCandles"
+
+	archived, err := s.archived(ctx, cryptocurrencyID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !archived {
+		return s.HistoryStorage.GetCandles(ctx, cryptocurrencyID, interval, from, to)
+	}
+
+	acc := newCandleAccumulator(interval)
+	err = s.Export(ctx, []int{cryptocurrencyID}, from, to, math.MaxInt64, func(h *entity.PriceHistory) error {
+		acc.add(h)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return acc.candles, nil
+}
+
+// archived reports whether archives of the cryptocurrency overlap [from, to].
+func (s *ArchiveService) archived(ctx context.Context, cryptocurrencyID int, from, to time.Time) (bool, error) {
+	archives, err := s.ast.GetOverlapping(ctx, cryptocurrencyID, from, to)
+	if err != nil {
+		return false, err
+	}
+	return len(archives) > 0, nil
+}
+
+// nearest returns the sample closer to timestamp, preferring the earlier one on ties.
+func nearest(a, b *entity.PriceHistory, timestamp time.Time) *entity.PriceHistory {
+	if a == nil {
+		return b
+	}
+
+	da, db := a.Timestamp.Sub(timestamp).Abs(), b.Timestamp.Sub(timestamp).Abs()
+	if db < da || (db == da && b.Timestamp.Before(a.Timestamp)) {
+		return b
+	}
+	return a
+}
+
+// Export streams stored and archived samples of cryptocurrencies in [from, to]
+// ordered by cryptocurrency and timestamp.
+func (s *ArchiveService) Export(
+	ctx context.Context,
+	cryptocurrencyIDs []int,
+	from, to time.Time,
+	limit int64,
+	fn func(history *entity.PriceHistory) error,
+) error {
+	const op = "ArchiveService.Export"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Time("from", from),
+		slog.Time("to", to))
+
+	ids := slices.Clone(cryptocurrencyIDs)
+	slices.Sort(ids)
+
+	archived := make(map[int][]entity.PriceHistory, len(ids))
+	for _, id := range ids {
+		archives, err := s.ast.GetOverlapping(ctx, id, from, to)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get archives! Error: %s", err))
+			return err
+		}
+
+		for _, a := range archives {
+			histories, err := s.read(ctx, a)
+			if err != nil {
+				log.Error(fmt.Sprintf("fail to read archive %s! Error: %s", a.Key, err))
+				return err
+			}
+
+			for _, h := range histories {
+				if !h.Timestamp.Before(from) && !h.Timestamp.After(to) {
+					archived[id] = append(archived[id], h)
+				}
+			}
+		}
+	}
+
+	if len(archived) == 0 {
+		return s.HistoryStorage.Export(ctx, cryptocurrencyIDs, from, to, limit, fn)
+	}
+
+	var n int64
+	emit := func(h *entity.PriceHistory) error {
+		if n == limit {
+			return errArchiveLimit
+		}
+		n++
+		return fn(h)
+	}
+
+	for _, id := range ids {
+		// Samples imported after archiving may interleave with archived ones.
+		rest := archived[id]
+		slices.SortStableFunc(rest, func(a, b entity.PriceHistory) int {
+			return a.Timestamp.Compare(b.Timestamp)
+		})
+
+		err := s.HistoryStorage.Export(ctx, []int{id}, from, to, limit-n, func(h *entity.PriceHistory) error {
+			for len(rest) > 0 && rest[0].Timestamp.Before(h.Timestamp) {
+				if err := emit(&rest[0]); err != nil {
+					return err
+				}
+				rest = rest[1:]
+			}
+			return emit(h)
+		})
+		for err == nil && len(rest) > 0 {
+			err = emit(&rest[0])
+			rest = rest[1:]
+		}
+		if errors.Is(err, errArchiveLimit) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+)
+
+// archiveCache keeps decoded archives keyed by archive ID, evicting the least
+// recently used ones once they hold more than maxRows samples. Archives are
+// immutable once committed, so cached samples never go stale.
+type archiveCache struct {
+	mu      sync.Mutex
+	maxRows int
+	rows    int
+	order   *list.List
+	items   map[int]*list.Element
+}
+
+type archiveCacheEntry struct {
+	id        int
+	histories []entity.PriceHistory
+}
+
+func newArchiveCache(maxRows int) *archiveCache {
+	return &archiveCache{
+		maxRows: maxRows,
+		order:   list.New(),
+		items:   make(map[int]*list.Element),
+	}
+}
+
+func (c *archiveCache) get(id int) ([]entity.PriceHistory, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[id]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*archiveCacheEntry).histories, true
+}
+
+// put caches histories of the archive unless they alone exceed the limit.
+func (c *archiveCache) put(id int, histories []entity.PriceHistory) {
+	if len(histories) > c.maxRows {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[id]; ok {
+		return
+	}
+	c.items[id] = c.order.PushFront(&archiveCacheEntry{id: id, histories: histories})
+	c.rows += len(histories)
+
+	for c.rows > c.maxRows {
+		last := c.order.Back()
+		entry := c.order.Remove(last).(*archiveCacheEntry)
+		delete(c.items, entry.id)
+		c.rows -= len(entry.histories)
+	}
+}
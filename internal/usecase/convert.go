@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const DefaultConvertTolerance = 5 * time.Minute
+
+// Convert derives the from/to cross rate via the common quote currency.
+// Each leg uses the nearest stored price and must lie within tolerance.
+func (s *CryptocurrencyService) Convert(
+	ctx context.Context,
+	from, to string,
+	amount decimal.Decimal,
+	timestamp time.Time,
+	tolerance time.Duration,
+) (*entity.Conversion, error) {
+	const op = "CryptocurrencyService.Convert"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("from", from),
+		slog.String("to", to),
+		slog.Time("timestamp", timestamp))
+
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.String("from", from), attribute.String("to", to)))
+	defer span.End()
+
+	log.Debug("trying to convert cryptocurrency")
+	conv := &entity.Conversion{
+		From:      from,
+		To:        to,
+		Quote:     s.quote,
+		Amount:    amount,
+		Timestamp: timestamp,
+		Legs:      make([]entity.ConversionLeg, 0, 2),
+	}
+
+	fromPrice, err := s.legPrice(ctx, conv, from, timestamp, tolerance)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get from leg price! Error: %s", err))
+		return nil, err
+	}
+
+	toPrice, err := s.legPrice(ctx, conv, to, timestamp, tolerance)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get to leg price! Error: %s", err))
+		return nil, err
+	}
+
+	if toPrice.IsZero() {
+		log.Error("to leg price is zero!")
+		return nil, common.ErrUnexpected
+	}
+
+	conv.Rate = fromPrice.Div(toPrice)
+	conv.Result = amount.Mul(conv.Rate)
+	log.Debug("successfully converted cryptocurrency")
+
+	return conv, nil
+}
+
+// legPrice returns the quote price of symbol and appends the used sample to conv.
+// The quote currency itself is priced at 1 without a lookup.
+func (s *CryptocurrencyService) legPrice(
+	ctx context.Context,
+	conv *entity.Conversion,
+	symbol string,
+	timestamp time.Time,
+	tolerance time.Duration,
+) (decimal.Decimal, error) {
+	if symbol == s.quote {
+		return decimal.NewFromInt(1), nil
+	}
+
+	cr, err := s.cst.GetBySymbol(ctx, symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	hist, err := s.hst.GetNearestPrice(ctx, cr.ID, timestamp)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	skew := hist.Timestamp.Sub(timestamp).Abs()
+	if skew > tolerance {
+		return decimal.Zero, fmt.Errorf("%s is %s away from requested time: %w", symbol, skew, common.ErrPriceOutOfTolerance)
+	}
+
+	conv.Legs = append(conv.Legs, entity.ConversionLeg{
+		Symbol: symbol,
+		Sample: hist,
+		Skew:   skew,
+	})
+
+	return hist.Price, nil
+}
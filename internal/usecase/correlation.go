@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/correlation"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxCorrelationPoints = 10000
+
+// Correlation aligns candle closes of symbols on a common grid of interval,
+// forward-fills missing samples and correlates their returns.
+func (s *CryptocurrencyService) Correlation(
+	ctx context.Context,
+	symbols []string,
+	method string,
+	interval time.Duration,
+	from, to time.Time,
+) (*entity.CorrelationMatrix, error) {
+	const op = "CryptocurrencyService.Correlation"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Any("symbols", symbols),
+		slog.String("method", method),
+		slog.Duration("interval", interval))
+
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.StringSlice("symbols", symbols), attribute.String("method", method)))
+	defer span.End()
+
+	log.Debug("trying to compute correlation")
+	step := int64(interval / time.Second)
+	start := from.Unix() - from.Unix()%step
+	if (to.Unix()-start)/step > maxCorrelationPoints {
+		log.Error("too many points in window!")
+		return nil, common.ErrWindowTooLarge
+	}
+
+	grid := make([]int64, 0, (to.Unix()-start)/step+1)
+	for t := start; t <= to.Unix(); t += step {
+		grid = append(grid, t)
+	}
+
+	series := make([]map[int64]float64, len(symbols))
+	for i, symbol := range symbols {
+		cr, err := s.cst.GetBySymbol(ctx, symbol)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get cryptocurrency by symbol %s! Error: %s", symbol, err))
+			return nil, err
+		}
+
+		candles, err := s.hst.GetCandles(ctx, cr.ID, interval, from, to)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get candles of %s! Error: %s", symbol, err))
+			return nil, err
+		}
+
+		closes := make(map[int64]float64, len(candles))
+		for _, c := range candles {
+			closes[c.Timestamp.Unix()] = c.Close.InexactFloat64()
+		}
+		series[i] = closes
+	}
+
+	returns := correlation.Returns(correlation.ForwardFill(grid, series))
+	points := len(returns[0])
+	if points < 2 {
+		log.Error("not enough aligned points!")
+		return nil, common.ErrHistoryNotFound
+	}
+	log.Debug("successfully computed correlation")
+
+	return &entity.CorrelationMatrix{
+		Symbols:  symbols,
+		Method:   method,
+		Interval: interval,
+		Points:   points,
+		Matrix:   correlation.Matrix(returns, method),
+	}, nil
+}
@@ -9,48 +9,82 @@ import (
 
 	"github.com/Homyakadze14/AFFARM_tz/internal/common"
 	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type CryptocurrencyStorage interface {
 	GetBySymbol(ctx context.Context, symbol string) (*entity.Cryptocurrency, error)
 	CreateOrGet(ctx context.Context, c *entity.Cryptocurrency) (*entity.Cryptocurrency, error)
+	Lock(ctx context.Context, id int) error
+}
+
+// TxManager runs fn in one transaction carried by the context passed to fn.
+type TxManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type TrackingStorage interface {
 	Create(ctx context.Context, trc *entity.Tracking) (*entity.Tracking, error)
 	GetByCryptocurrencyID(ctx context.Context, crid int) (*entity.Tracking, error)
 	Update(ctx context.Context, trc *entity.Tracking) (*entity.Tracking, error)
+	Notify(ctx context.Context, coin *entity.TrackedCoin, active bool) error
 }
 
 type HistoryStorage interface {
 	GetNearestPrice(ctx context.Context, cryptocurrencyID int, timestamp time.Time) (*entity.PriceHistory, error)
+	GetNearestPrices(ctx context.Context, cryptocurrencyID int, timestamps []time.Time) ([]*entity.PriceHistory, error)
+	GetStats(ctx context.Context, cryptocurrencyID int, from, to time.Time) (*entity.PriceStats, error)
+	GetCandles(ctx context.Context, cryptocurrencyID int, interval time.Duration, from, to time.Time) ([]entity.Candle, error)
+	Export(ctx context.Context, cryptocurrencyIDs []int, from, to time.Time, limit int64, fn func(history *entity.PriceHistory) error) error
 }
 
 type CryptoClient interface {
-	SymbolExists(symbol string) (bool, error)
+	SymbolExists(ctx context.Context, symbol string) (bool, error)
+}
+
+type FXConverter interface {
+	Rate(ctx context.Context, currency string, at time.Time) (decimal.Decimal, error)
 }
 
 type Parser interface {
-	AddCoin(c entity.Cryptocurrency)
+	AddCoin(c entity.TrackedCoin)
 	RemoveCoin(c entity.Cryptocurrency)
 }
 
+// KeepSchedule selects schedule fields of an already tracked coin which Add
+// keeps instead of taking them from the coin.
+type KeepSchedule struct {
+	Interval bool
+	Priority bool
+}
+
 type CryptocurrencyService struct {
 	log         *slog.Logger
 	cst         CryptocurrencyStorage
 	tst         TrackingStorage
 	hst         HistoryStorage
 	cryptoCient CryptoClient
+	fx          FXConverter
 	parser      Parser
+	tm          TxManager
+	quote       string
 }
 
+// NewCryptocurrencyService creates the service of cryptocurrencies whose
+// prices are stored in the quote currency.
 func NewCryptocurrencyService(
 	log *slog.Logger,
 	cst CryptocurrencyStorage,
 	tst TrackingStorage,
 	hst HistoryStorage,
 	cryptoCient CryptoClient,
+	fx FXConverter,
 	parser Parser,
+	tm TxManager,
+	quote string,
 ) *CryptocurrencyService {
 	return &CryptocurrencyService{
 		log:         log,
@@ -58,71 +92,127 @@ func NewCryptocurrencyService(
 		tst:         tst,
 		hst:         hst,
 		cryptoCient: cryptoCient,
+		fx:          fx,
 		parser:      parser,
+		tm:          tm,
+		quote:       quote,
 	}
 }
 
-func (s *CryptocurrencyService) Add(ctx context.Context, cr *entity.Cryptocurrency) error {
+// Quote returns the currency prices are stored in.
+func (s *CryptocurrencyService) Quote() string {
+	return s.quote
+}
+
+// Add starts tracking the coin with its polling interval and priority, or
+// updates the schedule of an already tracked coin. Coins without polling
+// interval are polled with the current default interval of the parser. It reports
+// whether the coin was not tracked before. Fields selected by keep retain
+// their current values for an already tracked coin and are set in coin.
+func (s *CryptocurrencyService) Add(ctx context.Context, coin *entity.TrackedCoin, keep KeepSchedule) (bool, error) {
 	const op = "CryptocurrencyService.Add"
-	log := s.log.With(slog.String("op", op),
-		slog.String("symbol", cr.Symbol))
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("symbol", coin.Symbol))
+
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.String("symbol", coin.Symbol)))
+	defer span.End()
+
+	if coin.PollInterval < 0 {
+		coin.PollInterval = 0
+	}
+	cr := &coin.Cryptocurrency
 
 	log.Debug("trying to add cryptocurrency")
-	exists, err := s.cryptoCient.SymbolExists(cr.Symbol)
+	exists, err := s.cryptoCient.SymbolExists(ctx, cr.Symbol)
 	if err != nil {
 		log.Error(fmt.Sprintf("fail to check existance! Error: %s", err))
-		return err
+		return false, err
 	}
 
 	if !exists {
 		log.Error("symbol doesn't exists!")
-		return common.ErrSymbolNotFound
+		return false, common.ErrSymbolNotFound
 	}
 
-	cr, err = s.cst.CreateOrGet(ctx, cr)
-	if err != nil {
-		log.Error(fmt.Sprintf("fail to create or get cryptocurrency! Error: %s", err))
-		return err
-	}
-
-	trc, err := s.tst.GetByCryptocurrencyID(ctx, cr.ID)
-	if err != nil {
-		if !errors.Is(err, common.ErrTrackingNotFound) {
-			log.Error(fmt.Sprintf("fail to get tracking! Error: %s", err))
+	changed, created := false, false
+	err = s.tm.Do(ctx, func(ctx context.Context) error {
+		stored, err := s.cst.CreateOrGet(ctx, cr)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to create or get cryptocurrency! Error: %s", err))
 			return err
 		}
+		*cr = *stored
 
-		tr := &entity.Tracking{
-			CryptocurrencyID: cr.ID,
-			IsActive:         true,
-		}
-		trc, err = s.tst.Create(ctx, tr)
-		if err != nil {
-			log.Error(fmt.Sprintf("fail to create tracking! Error: %s", err))
+		if err := s.cst.Lock(ctx, cr.ID); err != nil {
+			log.Error(fmt.Sprintf("fail to lock cryptocurrency! Error: %s", err))
 			return err
 		}
-		s.parser.AddCoin(*cr)
-	}
 
-	if !trc.IsActive {
-		trc.IsActive = true
-		_, err = s.tst.Update(ctx, trc)
+		trc, err := s.tst.GetByCryptocurrencyID(ctx, cr.ID)
 		if err != nil {
-			log.Error(fmt.Sprintf("fail to update tracking! Error: %s", err))
-			return err
+			if !errors.Is(err, common.ErrTrackingNotFound) {
+				log.Error(fmt.Sprintf("fail to get tracking! Error: %s", err))
+				return err
+			}
+
+			tr := &entity.Tracking{
+				CryptocurrencyID: cr.ID,
+				IsActive:         true,
+				PollInterval:     coin.PollInterval,
+				Priority:         coin.Priority,
+			}
+			_, err = s.tst.Create(ctx, tr)
+			if err != nil {
+				log.Error(fmt.Sprintf("fail to create tracking! Error: %s", err))
+				return err
+			}
+			changed, created = true, true
+			return s.notify(ctx, log, coin, true)
+		}
+
+		if keep.Interval {
+			coin.PollInterval = trc.PollInterval
+		}
+		if keep.Priority {
+			coin.Priority = trc.Priority
 		}
-		s.parser.AddCoin(*cr)
+
+		if !trc.IsActive || trc.PollInterval != coin.PollInterval || trc.Priority != coin.Priority {
+			created = !trc.IsActive
+			trc.IsActive = true
+			trc.PollInterval = coin.PollInterval
+			trc.Priority = coin.Priority
+			_, err = s.tst.Update(ctx, trc)
+			if err != nil {
+				log.Error(fmt.Sprintf("fail to update tracking! Error: %s", err))
+				return err
+			}
+			changed = true
+			return s.notify(ctx, log, coin, true)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// The parser only learns about committed changes.
+	if changed {
+		s.parser.AddCoin(*coin)
 	}
 	log.Debug("successfully added cryptocurrency")
 
-	return nil
+	return created, nil
 }
 
 func (s *CryptocurrencyService) Remove(ctx context.Context, cr *entity.Cryptocurrency) error {
 	const op = "CryptocurrencyService.Remove"
-	log := s.log.With(slog.String("op", op),
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
 		slog.String("symbol", cr.Symbol))
 
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.String("symbol", cr.Symbol)))
+	defer span.End()
+
 	log.Debug("trying to remove cryptocurrency")
 	cr, err := s.cst.GetBySymbol(ctx, cr.Symbol)
 	if err != nil {
@@ -130,16 +220,28 @@ func (s *CryptocurrencyService) Remove(ctx context.Context, cr *entity.Cryptocur
 		return err
 	}
 
-	tr, err := s.tst.GetByCryptocurrencyID(ctx, cr.ID)
-	if err != nil {
-		log.Error(fmt.Sprintf("fail to get tracking by cryptocurrency! Error: %s", err))
-		return err
-	}
+	err = s.tm.Do(ctx, func(ctx context.Context) error {
+		if err := s.cst.Lock(ctx, cr.ID); err != nil {
+			log.Error(fmt.Sprintf("fail to lock cryptocurrency! Error: %s", err))
+			return err
+		}
+
+		tr, err := s.tst.GetByCryptocurrencyID(ctx, cr.ID)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get tracking by cryptocurrency! Error: %s", err))
+			return err
+		}
 
-	tr.IsActive = false
-	_, err = s.tst.Update(ctx, tr)
+		tr.IsActive = false
+		_, err = s.tst.Update(ctx, tr)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to update tracking! Error: %s", err))
+			return err
+		}
+
+		return s.notify(ctx, log, &entity.TrackedCoin{Cryptocurrency: *cr}, false)
+	})
 	if err != nil {
-		log.Error(fmt.Sprintf("fail to update tracking! Error: %s", err))
 		return err
 	}
 
@@ -149,12 +251,26 @@ func (s *CryptocurrencyService) Remove(ctx context.Context, cr *entity.Cryptocur
 	return nil
 }
 
-func (s *CryptocurrencyService) Price(ctx context.Context, symbol string, timestamp time.Time) (*entity.PriceHistory, error) {
+// notify publishes the tracking change to parsers of other replicas.
+func (s *CryptocurrencyService) notify(ctx context.Context, log *slog.Logger, coin *entity.TrackedCoin, active bool) error {
+	if err := s.tst.Notify(ctx, coin, active); err != nil {
+		log.Error(fmt.Sprintf("fail to notify tracking change! Error: %s", err))
+		return err
+	}
+	return nil
+}
+
+// Price returns the nearest price of symbol converted to currency at the sample's time.
+func (s *CryptocurrencyService) Price(ctx context.Context, symbol string, timestamp time.Time, currency string) (*entity.PriceHistory, error) {
 	const op = "CryptocurrencyService.Price"
-	log := s.log.With(slog.String("op", op),
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
 		slog.String("symbol", symbol),
+		slog.String("currency", currency),
 		slog.Time("timestamp", timestamp))
 
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.String("symbol", symbol), attribute.String("currency", currency)))
+	defer span.End()
+
 	log.Debug("trying to get price of cryptocurrency")
 	cr, err := s.cst.GetBySymbol(ctx, symbol)
 	if err != nil {
@@ -167,7 +283,44 @@ func (s *CryptocurrencyService) Price(ctx context.Context, symbol string, timest
 		log.Error(fmt.Sprintf("fail to get nearest price! Error: %s", err))
 		return nil, err
 	}
+
+	rate, err := s.fx.Rate(ctx, currency, hist.Timestamp)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get fx rate! Error: %s", err))
+		return nil, err
+	}
+	hist.Price = hist.Price.Mul(rate)
 	log.Debug("successfully got price of cryptocurrency")
 
 	return hist, nil
 }
+
+// Stats returns price stats of symbol in [from, to] in currency, the quote
+// currency when empty.
+func (s *CryptocurrencyService) Stats(ctx context.Context, symbol string, from, to time.Time, currency string) (*entity.PriceStats, error) {
+	const op = "CryptocurrencyService.Stats"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("symbol", symbol),
+		slog.String("currency", currency),
+		slog.Time("from", from),
+		slog.Time("to", to))
+
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.String("symbol", symbol)))
+	defer span.End()
+
+	log.Debug("trying to get price stats of cryptocurrency")
+	cr, err := s.cst.GetBySymbol(ctx, symbol)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get cryptocurrency by symbol! Error: %s", err))
+		return nil, err
+	}
+
+	stats, err := s.stats(ctx, cr.ID, from, to, currency)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get price stats! Error: %s", err))
+		return nil, err
+	}
+	log.Debug("successfully got price stats of cryptocurrency")
+
+	return stats, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+// priceConverter converts quote prices of samples to currency at their time.
+// FX rates are daily, so the rate of each day is looked up once.
+type priceConverter struct {
+	fx       FXConverter
+	currency string
+	rates    map[time.Time]decimal.Decimal
+}
+
+func (s *CryptocurrencyService) converter(currency string) *priceConverter {
+	return &priceConverter{fx: s.fx, currency: currency, rates: make(map[time.Time]decimal.Decimal)}
+}
+
+// convert returns a copy of the sample priced in the currency.
+func (c *priceConverter) convert(ctx context.Context, h *entity.PriceHistory) (*entity.PriceHistory, error) {
+	ts := h.Timestamp.UTC()
+	day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+
+	rate, ok := c.rates[day]
+	if !ok {
+		var err error
+		rate, err = c.fx.Rate(ctx, c.currency, h.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		c.rates[day] = rate
+	}
+
+	converted := *h
+	converted.Price = h.Price.Mul(rate)
+	return &converted, nil
+}
+
+// isQuote reports whether prices in currency need no conversion.
+func (s *CryptocurrencyService) isQuote(currency string) bool {
+	return currency == "" || currency == s.quote
+}
+
+// stats returns price stats in currency. Converted stats are aggregated over
+// samples priced at their own day's rate.
+func (s *CryptocurrencyService) stats(
+	ctx context.Context,
+	cryptocurrencyID int,
+	from, to time.Time,
+	currency string,
+) (*entity.PriceStats, error) {
+	if s.isQuote(currency) {
+		return s.hst.GetStats(ctx, cryptocurrencyID, from, to)
+	}
+
+	conv := s.converter(currency)
+	var acc statsAccumulator
+	err := s.hst.Export(ctx, []int{cryptocurrencyID}, from, to, math.MaxInt64, func(h *entity.PriceHistory) error {
+		h, err := conv.convert(ctx, h)
+		if err != nil {
+			return err
+		}
+		acc.add(h)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats, ok := acc.result()
+	if !ok {
+		return nil, common.ErrHistoryNotFound
+	}
+	return stats, nil
+}
+
+// candles returns candles in currency. Converted candles are built over
+// samples priced at their own day's rate.
+func (s *CryptocurrencyService) candles(
+	ctx context.Context,
+	cryptocurrencyID int,
+	interval time.Duration,
+	from, to time.Time,
+	currency string,
+) ([]entity.Candle, error) {
+	if s.isQuote(currency) {
+		return s.hst.GetCandles(ctx, cryptocurrencyID, interval, from, to)
+	}
+
+	conv := s.converter(currency)
+	acc := newCandleAccumulator(interval)
+	err := s.hst.Export(ctx, []int{cryptocurrencyID}, from, to, math.MaxInt64, func(h *entity.PriceHistory) error {
+		h, err := conv.convert(ctx, h)
+		if err != nil {
+			return err
+		}
+		acc.add(h)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return acc.candles, nil
+}
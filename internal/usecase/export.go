@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Cryptocurrencies resolves symbols to cryptocurrencies.
+func (s *CryptocurrencyService) Cryptocurrencies(ctx context.Context, symbols []string) ([]entity.Cryptocurrency, error) {
+	const op = "CryptocurrencyService.Cryptocurrencies"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Any("symbols", symbols))
+
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.StringSlice("symbols", symbols)))
+	defer span.End()
+
+	crs := make([]entity.Cryptocurrency, 0, len(symbols))
+	for _, symbol := range symbols {
+		cr, err := s.cst.GetBySymbol(ctx, symbol)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get cryptocurrency by symbol %s! Error: %s", symbol, err))
+			return nil, err
+		}
+		crs = append(crs, *cr)
+	}
+
+	return crs, nil
+}
+
+// Export streams up to maxRows samples of crs in [from, to] priced in
+// currency, the quote currency when empty, to fn. It returns
+// common.ErrExportLimitExceeded when more rows are available.
+func (s *CryptocurrencyService) Export(
+	ctx context.Context,
+	crs []entity.Cryptocurrency,
+	from, to time.Time,
+	currency string,
+	maxRows int64,
+	fn func(symbol string, history *entity.PriceHistory) error,
+) (int64, error) {
+	const op = "CryptocurrencyService.Export"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Time("from", from),
+		slog.Time("to", to),
+		slog.String("currency", currency),
+		slog.Int64("max_rows", maxRows))
+
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.Int("coins", len(crs))))
+	defer span.End()
+
+	log.Debug("trying to export price history")
+	ids := make([]int, 0, len(crs))
+	symbols := make(map[int]string, len(crs))
+	for _, cr := range crs {
+		ids = append(ids, cr.ID)
+		symbols[cr.ID] = cr.Symbol
+	}
+
+	conv := s.converter(currency)
+	var n int64
+	err := s.hst.Export(ctx, ids, from, to, maxRows+1, func(h *entity.PriceHistory) error {
+		if n == maxRows {
+			return common.ErrExportLimitExceeded
+		}
+		if !s.isQuote(currency) {
+			var err error
+			if h, err = conv.convert(ctx, h); err != nil {
+				return err
+			}
+		}
+		n++
+		return fn(symbols[h.CryptocurrencyID], h)
+	})
+	if err != nil {
+		if !errors.Is(err, common.ErrExportLimitExceeded) {
+			log.Error(fmt.Sprintf("fail to export price history! Error: %s", err))
+		}
+		return n, err
+	}
+	log.Debug(fmt.Sprintf("successfully exported %d rows", n))
+
+	return n, nil
+}
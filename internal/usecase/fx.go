@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"github.com/shopspring/decimal"
+)
+
+type FXRateStorage interface {
+	Upsert(ctx context.Context, rates []entity.FXRate) (int, error)
+	GetEffective(ctx context.Context, currency string, at time.Time) (*entity.FXRate, error)
+}
+
+// FXSource provides fiat rates against the quote currency.
+type FXSource interface {
+	Fetch(ctx context.Context) ([]entity.FXRate, error)
+}
+
+type FXService struct {
+	log   *slog.Logger
+	quote string
+	rst   FXRateStorage
+	src   FXSource
+}
+
+// NewFXService creates the service of rates of fiat currencies against the
+// quote currency.
+func NewFXService(
+	log *slog.Logger,
+	quote string,
+	rst FXRateStorage,
+	src FXSource,
+) *FXService {
+	return &FXService{
+		log:   log,
+		quote: quote,
+		rst:   rst,
+		src:   src,
+	}
+}
+
+// Sync imports rates from the source into the storage.
+func (s *FXService) Sync(ctx context.Context) (int, error) {
+	const op = "FXService.Sync"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op))
+
+	log.Debug("trying to sync fx rates")
+	rates, err := s.src.Fetch(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to fetch fx rates! Error: %s", err))
+		return 0, err
+	}
+
+	n, err := s.rst.Upsert(ctx, rates)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to upsert fx rates! Error: %s", err))
+		return 0, err
+	}
+	log.Info(fmt.Sprintf("synced %d fx rates", n))
+
+	return n, nil
+}
+
+// Rate returns the currency rate effective at the given time.
+// An empty currency or the quote currency has rate 1.
+func (s *FXService) Rate(ctx context.Context, currency string, at time.Time) (decimal.Decimal, error) {
+	const op = "FXService.Rate"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("currency", currency),
+		slog.Time("at", at))
+
+	if currency == "" || currency == s.quote {
+		return decimal.NewFromInt(1), nil
+	}
+
+	rate, err := s.rst.GetEffective(ctx, currency, at)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get effective rate! Error: %s", err))
+		return decimal.Zero, err
+	}
+
+	return rate.Rate, nil
+}
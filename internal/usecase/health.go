@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+)
+
+const pingTimeout = 2 * time.Second
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Heartbeater reports when a background loop last ran.
+type Heartbeater interface {
+	Heartbeat() time.Time
+}
+
+type CircuitBreaker interface {
+	CircuitState() string
+}
+
+type HealthService struct {
+	log              *slog.Logger
+	db               Pinger
+	parser           Heartbeater
+	exchange         CircuitBreaker
+	heartbeatTimeout time.Duration
+}
+
+// NewHealthService creates the readiness checks of the database, the parser
+// loop, which is stale after heartbeatTimeout, and the exchange circuit.
+func NewHealthService(
+	log *slog.Logger,
+	db Pinger,
+	parser Heartbeater,
+	exchange CircuitBreaker,
+	heartbeatTimeout time.Duration,
+) *HealthService {
+	return &HealthService{
+		log:              log,
+		db:               db,
+		parser:           parser,
+		exchange:         exchange,
+		heartbeatTimeout: heartbeatTimeout,
+	}
+}
+
+// Ready checks every dependency of the replica. Only local faults make it
+// not ready: an open exchange circuit fails on every replica alike, so
+// taking them out of rotation would not help.
+func (s *HealthService) Ready(ctx context.Context) entity.Readiness {
+	const op = "HealthService.Ready"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op))
+
+	components := []entity.ComponentHealth{
+		s.checkDatabase(ctx),
+		s.checkParser(),
+		s.checkExchange(),
+	}
+
+	readiness := entity.Readiness{Ready: true, Components: components}
+	for _, c := range components {
+		switch {
+		case !c.Healthy:
+			readiness.Ready = false
+			log.Warn(fmt.Sprintf("%s is not healthy! Error: %s", c.Name, c.Error))
+		case c.Degraded:
+			readiness.Degraded = true
+			log.Warn(fmt.Sprintf("%s is degraded! Error: %s", c.Name, c.Error))
+		}
+	}
+
+	return readiness
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) entity.ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.db.Ping(ctx)
+	c := entity.ComponentHealth{Name: "database", Healthy: err == nil, Latency: time.Since(start)}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	return c
+}
+
+func (s *HealthService) checkParser() entity.ComponentHealth {
+	heartbeat := s.parser.Heartbeat()
+	c := entity.ComponentHealth{Name: "parser", Heartbeat: heartbeat}
+	switch {
+	case heartbeat.IsZero():
+		c.Error = "parser has not started"
+	case time.Since(heartbeat) > s.heartbeatTimeout:
+		c.Error = fmt.Sprintf("no heartbeat for %s", time.Since(heartbeat).Round(time.Second))
+	default:
+		c.Healthy = true
+	}
+	return c
+}
+
+func (s *HealthService) checkExchange() entity.ComponentHealth {
+	state := s.exchange.CircuitState()
+	c := entity.ComponentHealth{Name: "exchange", State: state, Healthy: true, Degraded: state == entity.CircuitOpen}
+	if c.Degraded {
+		c.Error = "circuit is open after consecutive failures"
+	}
+	return c
+}
@@ -0,0 +1,322 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	importBatchSize    = 5000
+	importMaxRowErrors = 1000
+	maxSymbolLength    = 10
+)
+
+// maxImportPrice is the exclusive upper bound of NUMERIC(20, 8).
+var maxImportPrice = decimal.New(1, 12)
+
+type HistoryImporter interface {
+	Upsert(ctx context.Context, histories []entity.PriceHistory) (inserted, updated int64, err error)
+}
+
+type ImportService struct {
+	log         *slog.Logger
+	cst         CryptocurrencyStorage
+	hst         HistoryImporter
+	cryptoCient CryptoClient
+}
+
+func NewImportService(
+	log *slog.Logger,
+	cst CryptocurrencyStorage,
+	hst HistoryImporter,
+	cryptoCient CryptoClient,
+) *ImportService {
+	return &ImportService{
+		log:         log,
+		cst:         cst,
+		hst:         hst,
+		cryptoCient: cryptoCient,
+	}
+}
+
+type importRow struct {
+	line      int
+	symbol    string
+	timestamp time.Time
+	price     decimal.Decimal
+}
+
+type sampleKey struct {
+	cryptocurrencyID int
+	timestamp        int64
+}
+
+// importState accumulates a batch and the report of an import. Duplicates
+// are only tracked within the batch, the unique index of price history
+// upserts samples repeated in later batches. Symbols unknown to the exchange
+// have the zero ID.
+type importState struct {
+	report  *entity.ImportReport
+	ids     map[string]int
+	seen    map[sampleKey]int
+	pending []entity.PriceHistory
+}
+
+func (st *importState) fail(line int, err error) {
+	st.report.Failed++
+	if len(st.report.Errors) < importMaxRowErrors {
+		st.report.Errors = append(st.report.Errors, entity.ImportRowError{Line: line, Error: err.Error()})
+	}
+}
+
+// Import validates rows of symbol, timestamp and price read from r in format
+// and upserts them into price history in batches. Unknown cryptocurrencies
+// are created on demand if the exchange lists them, as by Add. Invalid rows
+// are skipped and reported by line.
+func (s *ImportService) Import(ctx context.Context, format string, r io.Reader) (*entity.ImportReport, error) {
+	const op = "ImportService.Import"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("format", format))
+
+	log.Debug("trying to import price history")
+	st := &importState{
+		report: &entity.ImportReport{
+			Created: make([]string, 0),
+			Errors:  make([]entity.ImportRowError, 0),
+		},
+		ids:     make(map[string]int),
+		seen:    make(map[sampleKey]int),
+		pending: make([]entity.PriceHistory, 0, importBatchSize),
+	}
+
+	handle := func(row *importRow, rowErr error) error {
+		st.report.Rows++
+		if rowErr != nil {
+			st.fail(row.line, rowErr)
+			return nil
+		}
+		return s.add(ctx, st, row)
+	}
+
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		err = readCSV(r, handle)
+	case ImportFormatNDJSON:
+		err = readNDJSON(r, handle)
+	default:
+		err = fmt.Errorf("unknown import format %q", format)
+	}
+	if err == nil {
+		err = s.flush(ctx, st)
+	}
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to import price history! Error: %s", err))
+		return st.report, err
+	}
+	log.Info(fmt.Sprintf("imported %d rows: %d inserted, %d updated, %d skipped, %d failed",
+		st.report.Rows, st.report.Inserted, st.report.Updated, st.report.Skipped, st.report.Failed))
+
+	return st.report, nil
+}
+
+func (s *ImportService) add(ctx context.Context, st *importState, row *importRow) error {
+	id, ok := st.ids[row.symbol]
+	if !ok {
+		cr, err := s.cst.GetBySymbol(ctx, row.symbol)
+		if err != nil {
+			if !errors.Is(err, common.ErrCryptocurrencyNotFound) {
+				return err
+			}
+
+			cr, err = s.create(ctx, st, row.symbol)
+			if err != nil {
+				return err
+			}
+		}
+		if cr != nil {
+			id = cr.ID
+		}
+		st.ids[row.symbol] = id
+	}
+	if id == 0 {
+		st.fail(row.line, common.ErrSymbolNotFound)
+		return nil
+	}
+
+	key := sampleKey{cryptocurrencyID: id, timestamp: row.timestamp.UnixMicro()}
+	if first, dup := st.seen[key]; dup {
+		st.fail(row.line, fmt.Errorf("duplicate of line %d", first))
+		return nil
+	}
+	st.seen[key] = row.line
+
+	st.pending = append(st.pending, entity.PriceHistory{
+		CryptocurrencyID: id,
+		Price:            row.price,
+		Timestamp:        row.timestamp,
+	})
+	if len(st.pending) == importBatchSize {
+		return s.flush(ctx, st)
+	}
+
+	return nil
+}
+
+// create creates the cryptocurrency of symbol, or returns nil if the exchange
+// does not list it.
+func (s *ImportService) create(ctx context.Context, st *importState, symbol string) (*entity.Cryptocurrency, error) {
+	exists, err := s.cryptoCient.SymbolExists(ctx, symbol)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	cr, err := s.cst.CreateOrGet(ctx, &entity.Cryptocurrency{Symbol: symbol})
+	if err != nil {
+		return nil, err
+	}
+	st.report.Created = append(st.report.Created, symbol)
+
+	return cr, nil
+}
+
+func (s *ImportService) flush(ctx context.Context, st *importState) error {
+	if len(st.pending) == 0 {
+		return nil
+	}
+
+	inserted, updated, err := s.hst.Upsert(ctx, st.pending)
+	if err != nil {
+		return err
+	}
+	st.report.Inserted += inserted
+	st.report.Updated += updated
+	st.report.Skipped += int64(len(st.pending)) - inserted - updated
+	st.pending = st.pending[:0]
+	clear(st.seen)
+
+	return nil
+}
+
+func readCSV(r io.Reader, handle func(row *importRow, err error) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	// Lines are reported as in the file, where quoted fields may span lines.
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			if err := handle(&importRow{line: pe.StartLine}, pe.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if first && len(record) > 0 && strings.EqualFold(record[0], "symbol") {
+			continue
+		}
+
+		if len(record) != 3 {
+			err = handle(&importRow{line: line}, fmt.Errorf("expected 3 fields, got %d", len(record)))
+		} else {
+			err = handle(parseRow(line, record[0], record[1], record[2]))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type ndjsonRow struct {
+	Symbol    string          `json:"symbol"`
+	Timestamp json.RawMessage `json:"timestamp"`
+	Price     json.RawMessage `json:"price"`
+}
+
+func readNDJSON(r io.Reader, handle func(row *importRow, err error) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var err error
+		var raw ndjsonRow
+		if jerr := json.Unmarshal([]byte(text), &raw); jerr != nil {
+			err = handle(&importRow{line: line}, jerr)
+		} else {
+			err = handle(parseRow(line, raw.Symbol, unquote(raw.Timestamp), unquote(raw.Price)))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseRow validates raw fields. Symbols are matched in upper case as listed
+// by the exchange, timestamps are Unix seconds or RFC 3339.
+func parseRow(line int, symbol, timestamp, price string) (*importRow, error) {
+	row := &importRow{line: line, symbol: strings.ToUpper(strings.TrimSpace(symbol))}
+
+	if row.symbol == "" || len(row.symbol) > maxSymbolLength {
+		return row, fmt.Errorf("symbol must be 1 to %d characters", maxSymbolLength)
+	}
+
+	timestamp = strings.TrimSpace(timestamp)
+	if sec, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+		row.timestamp = time.Unix(sec, 0)
+	} else if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		row.timestamp = t
+	} else {
+		return row, fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+
+	p, err := decimal.NewFromString(strings.TrimSpace(price))
+	if err != nil || !p.IsPositive() || p.GreaterThanOrEqual(maxImportPrice) {
+		return row, fmt.Errorf("invalid price %q", price)
+	}
+	row.price = p
+
+	return row, nil
+}
+
+func unquote(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+)
+
+// fakeCurrencies stores cryptocurrencies by symbol.
+type fakeCurrencies map[string]int
+
+func (s fakeCurrencies) GetBySymbol(_ context.Context, symbol string) (*entity.Cryptocurrency, error) {
+	id, ok := s[symbol]
+	if !ok {
+		return nil, common.ErrCryptocurrencyNotFound
+	}
+	return &entity.Cryptocurrency{ID: id, Symbol: symbol}, nil
+}
+
+func (s fakeCurrencies) CreateOrGet(_ context.Context, c *entity.Cryptocurrency) (*entity.Cryptocurrency, error) {
+	if _, ok := s[c.Symbol]; !ok {
+		s[c.Symbol] = len(s) + 1
+	}
+	return &entity.Cryptocurrency{ID: s[c.Symbol], Symbol: c.Symbol}, nil
+}
+
+func (s fakeCurrencies) Lock(context.Context, int) error { return nil }
+
+// fakeExchange lists symbols.
+type fakeExchange []string
+
+func (e fakeExchange) SymbolExists(_ context.Context, symbol string) (bool, error) {
+	return slices.Contains(e, symbol), nil
+}
+
+// fakeImporter inserts every sample.
+type fakeImporter struct {
+	batches [][]entity.PriceHistory
+}
+
+func (i *fakeImporter) Upsert(_ context.Context, histories []entity.PriceHistory) (int64, int64, error) {
+	i.batches = append(i.batches, slices.Clone(histories))
+	return int64(len(histories)), 0, nil
+}
+
+func newTestImportService(stored fakeCurrencies, listed fakeExchange) (*ImportService, *fakeImporter) {
+	hst := &fakeImporter{}
+	return NewImportService(slog.New(slog.NewTextHandler(io.Discard, nil)), stored, hst, listed), hst
+}
+
+func TestParseRow(t *testing.T) {
+	tests := []struct {
+		name      string
+		symbol    string
+		timestamp string
+		price     string
+		want      *importRow
+		err       string
+	}{
+		{
+			name:      "unix seconds",
+			symbol:    " btc ",
+			timestamp: "1754578944",
+			price:     "117000.5",
+			want:      &importRow{symbol: "BTC", timestamp: time.Unix(1754578944, 0)},
+		},
+		{
+			name:      "rfc 3339",
+			symbol:    "ETH",
+			timestamp: "2025-08-07T15:02:24Z",
+			price:     "3600",
+			want:      &importRow{symbol: "ETH", timestamp: time.Unix(1754578944, 0)},
+		},
+		{name: "empty symbol", symbol: " ", timestamp: "1", price: "1", err: "symbol must be 1 to 10 characters"},
+		{name: "long symbol", symbol: "ABCDEFGHIJK", timestamp: "1", price: "1", err: "symbol must be 1 to 10 characters"},
+		{name: "invalid timestamp", symbol: "BTC", timestamp: "yesterday", price: "1", err: `invalid timestamp "yesterday"`},
+		{name: "zero price", symbol: "BTC", timestamp: "1", price: "0", err: `invalid price "0"`},
+		{name: "price out of range", symbol: "BTC", timestamp: "1", price: "1000000000000", err: `invalid price "1000000000000"`},
+		{name: "invalid price", symbol: "BTC", timestamp: "1", price: "abc", err: `invalid price "abc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := parseRow(7, tt.symbol, tt.timestamp, tt.price)
+			if row.line != 7 {
+				t.Errorf("got line %d, want 7", row.line)
+			}
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if row.symbol != tt.want.symbol || !row.timestamp.Equal(tt.want.timestamp) || row.price.String() != tt.price {
+				t.Errorf("got %s %s %s, want %s %s %s", row.symbol, row.timestamp, row.price,
+					tt.want.symbol, tt.want.timestamp, tt.price)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		inserted int64
+		created  []string
+		errors   []entity.ImportRowError
+	}{
+		{
+			name:   "csv",
+			format: ImportFormatCSV,
+			input: "symbol,timestamp,price\n" +
+				"btc,1754578944,117000\n" +
+				"BTC, 2025-08-07T15:03:24Z, 117001\n" +
+				"BTC,1754579064\n" +
+				"BTC,1754579124,-1\n",
+			inserted: 2,
+			errors: []entity.ImportRowError{
+				{Line: 4, Error: "expected 3 fields, got 2"},
+				{Line: 5, Error: `invalid price "-1"`},
+			},
+		},
+		{
+			name:   "csv lines of quoted fields",
+			format: ImportFormatCSV,
+			input: "BTC,1754578944,\"1\n2\"\n" +
+				"BTC,1754579004,\"broken\n" +
+				"BTC,1754579064,1\n",
+			errors: []entity.ImportRowError{
+				{Line: 1, Error: `invalid price "1\n2"`},
+				{Line: 3, Error: "extraneous or missing \" in quoted-field"},
+			},
+		},
+		{
+			name:   "ndjson",
+			format: ImportFormatNDJSON,
+			input: `{"symbol":"BTC","timestamp":1754578944,"price":"117000"}` + "\n" +
+				"\n" +
+				`{"symbol":"btc","timestamp":"2025-08-07T15:03:24Z","price":117001}` + "\n" +
+				`{"symbol":"BTC",` + "\n" +
+				`{"symbol":"BTC","timestamp":"later","price":"1"}` + "\n",
+			inserted: 2,
+			errors: []entity.ImportRowError{
+				{Line: 4, Error: "unexpected end of JSON input"},
+				{Line: 5, Error: `invalid timestamp "later"`},
+			},
+		},
+		{
+			name:   "symbols created if listed",
+			format: ImportFormatCSV,
+			input: "ETH,1754578944,3600\n" +
+				"DOGE,1754578944,0.2\n" +
+				"eth,1754579004,3601\n" +
+				"DOGE,1754579004,0.2\n",
+			inserted: 2,
+			created:  []string{"ETH"},
+			errors: []entity.ImportRowError{
+				{Line: 2, Error: common.ErrSymbolNotFound.Error()},
+				{Line: 4, Error: common.ErrSymbolNotFound.Error()},
+			},
+		},
+		{
+			name:   "duplicates in batch",
+			format: ImportFormatCSV,
+			input: "BTC,1754578944,117000\n" +
+				"BTC,2025-08-07T15:02:24Z,117001\n",
+			inserted: 1,
+			errors: []entity.ImportRowError{
+				{Line: 2, Error: "duplicate of line 1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestImportService(fakeCurrencies{"BTC": 1}, fakeExchange{"BTC", "ETH"})
+
+			report, err := s.Import(context.Background(), tt.format, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Inserted != tt.inserted || report.Failed != int64(len(tt.errors)) ||
+				report.Rows != report.Inserted+report.Failed {
+				t.Errorf("got %d rows, %d inserted, %d failed, want %d inserted, %d failed",
+					report.Rows, report.Inserted, report.Failed, tt.inserted, len(tt.errors))
+			}
+			if !slices.Equal(report.Created, tt.created) {
+				t.Errorf("got created %v, want %v", report.Created, tt.created)
+			}
+			if !slices.Equal(report.Errors, tt.errors) {
+				t.Errorf("got errors %v, want %v", report.Errors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestImportDedupesPerBatch(t *testing.T) {
+	var input strings.Builder
+	for i := range importBatchSize {
+		fmt.Fprintf(&input, "BTC,%d,1\n", 1754578944+i)
+	}
+	// A sample repeated in a later batch is left to the upsert.
+	input.WriteString("BTC,1754578944,2\n")
+
+	s, hst := newTestImportService(fakeCurrencies{"BTC": 1}, nil)
+	report, err := s.Import(context.Background(), ImportFormatCSV, strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Failed != 0 || report.Inserted != importBatchSize+1 {
+		t.Errorf("got %d inserted, %d failed, want %d inserted", report.Inserted, report.Failed, importBatchSize+1)
+	}
+	if len(hst.batches) != 2 || len(hst.batches[1]) != 1 {
+		t.Fatalf("got %d batches, want batches of %d and 1 samples", len(hst.batches), importBatchSize)
+	}
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	s, _ := newTestImportService(fakeCurrencies{}, nil)
+	if _, err := s.Import(context.Background(), "xml", strings.NewReader("")); err == nil {
+		t.Fatal("expected error of unknown format")
+	}
+}
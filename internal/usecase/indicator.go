@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/indicators"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	IndicatorSMA       = "sma"
+	IndicatorEMA       = "ema"
+	IndicatorRSI       = "rsi"
+	IndicatorBollinger = "bollinger"
+
+	bollingerDeviations = 2
+	maxIndicatorPoints  = 10000
+)
+
+// Indicator computes the named indicator over close prices of candles in
+// [from, to] in currency, the quote currency when empty. Intervals without samples between the first and the last
+// candle repeat the previous close, so periods always span equal time.
+func (s *CryptocurrencyService) Indicator(
+	ctx context.Context,
+	symbol, name string,
+	period int,
+	interval time.Duration,
+	from, to time.Time,
+	currency string,
+) (*entity.IndicatorSeries, error) {
+	const op = "CryptocurrencyService.Indicator"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("symbol", symbol),
+		slog.String("name", name),
+		slog.Int("period", period),
+		slog.Duration("interval", interval),
+		slog.String("currency", currency))
+
+	ctx, span := tracer.Start(ctx, op, trace.WithAttributes(attribute.String("symbol", symbol), attribute.String("name", name)))
+	defer span.End()
+
+	log.Debug("trying to compute indicator")
+	if int64(to.Sub(from)/interval) > maxIndicatorPoints {
+		log.Error("too many points in window!")
+		return nil, common.ErrWindowTooLarge
+	}
+
+	cr, err := s.cst.GetBySymbol(ctx, symbol)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get cryptocurrency by symbol! Error: %s", err))
+		return nil, err
+	}
+
+	candles, err := s.candles(ctx, cr.ID, interval, from, to, currency)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get candles! Error: %s", err))
+		return nil, err
+	}
+
+	if len(candles) == 0 {
+		log.Error("no candles in window!")
+		return nil, common.ErrHistoryNotFound
+	}
+	candles = fillCandles(candles, interval)
+
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close.InexactFloat64()
+	}
+
+	values, upper, lower := closes, []float64(nil), []float64(nil)
+	switch name {
+	case IndicatorSMA:
+		values = indicators.SMA(closes, period)
+	case IndicatorEMA:
+		values = indicators.EMA(closes, period)
+	case IndicatorRSI:
+		values = indicators.RSI(closes, period)
+	case IndicatorBollinger:
+		values, upper, lower = indicators.Bollinger(closes, period, bollingerDeviations)
+	default:
+		log.Error("unknown indicator!")
+		return nil, common.ErrUnknownIndicator
+	}
+
+	series := &entity.IndicatorSeries{
+		Name:     name,
+		Period:   period,
+		Interval: interval,
+		Points:   make([]entity.IndicatorPoint, len(candles)),
+	}
+	for i, c := range candles {
+		p := entity.IndicatorPoint{
+			Timestamp: c.Timestamp,
+			Value:     values[i],
+			Upper:     math.NaN(),
+			Lower:     math.NaN(),
+		}
+		if upper != nil {
+			p.Upper = upper[i]
+			p.Lower = lower[i]
+		}
+		series.Points[i] = p
+	}
+	log.Debug("successfully computed indicator")
+
+	return series, nil
+}
+
+// fillCandles inserts flat candles at the previous close, without samples,
+// into intervals missing between candles.
+func fillCandles(candles []entity.Candle, interval time.Duration) []entity.Candle {
+	filled := make([]entity.Candle, 0, len(candles))
+	for _, c := range candles {
+		if n := len(filled); n > 0 {
+			prev := filled[n-1].Close
+			for t := filled[n-1].Timestamp.Add(interval); t.Before(c.Timestamp); t = t.Add(interval) {
+				filled = append(filled, entity.Candle{Timestamp: t, Open: prev, High: prev, Low: prev, Close: prev})
+			}
+		}
+		filled = append(filled, c)
+	}
+	return filled
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+var candleStart = time.Unix(1754578800, 0)
+
+// candle returns the candle closing at price, minute intervals after
+// candleStart.
+func candle(minute int, price string) entity.Candle {
+	p := decimal.RequireFromString(price)
+	return entity.Candle{
+		Timestamp: candleStart.Add(time.Duration(minute) * time.Minute),
+		Open:      p,
+		High:      p,
+		Low:       p,
+		Close:     p,
+		Count:     1,
+	}
+}
+
+func TestFillCandles(t *testing.T) {
+	tests := []struct {
+		name    string
+		candles []entity.Candle
+		want    []entity.Candle
+	}{
+		{
+			name:    "no gaps",
+			candles: []entity.Candle{candle(0, "1"), candle(1, "2")},
+			want:    []entity.Candle{candle(0, "1"), candle(1, "2")},
+		},
+		{
+			// Nothing precedes the first candle to repeat.
+			name:    "gap at start",
+			candles: []entity.Candle{candle(2, "1"), candle(3, "2")},
+			want:    []entity.Candle{candle(2, "1"), candle(3, "2")},
+		},
+		{
+			name:    "gaps in middle",
+			candles: []entity.Candle{candle(0, "1"), candle(3, "2"), candle(5, "3")},
+			want: []entity.Candle{
+				candle(0, "1"), {Timestamp: candleStart.Add(time.Minute)}, {Timestamp: candleStart.Add(2 * time.Minute)},
+				candle(3, "2"), {Timestamp: candleStart.Add(4 * time.Minute)},
+				candle(5, "3"),
+			},
+		},
+		{
+			name:    "single candle",
+			candles: []entity.Candle{candle(0, "1")},
+			want:    []entity.Candle{candle(0, "1")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fillCandles(tt.candles, time.Minute)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d candles, want %d", len(got), len(tt.want))
+			}
+
+			var prev decimal.Decimal
+			for i, want := range tt.want {
+				// Zero wanted candles are filled at the previous close.
+				if want.Count == 0 {
+					want.Open, want.High, want.Low, want.Close = prev, prev, prev, prev
+				}
+				c := got[i]
+				if !c.Timestamp.Equal(want.Timestamp) || c.Count != want.Count ||
+					!c.Open.Equal(want.Open) || !c.High.Equal(want.High) ||
+					!c.Low.Equal(want.Low) || !c.Close.Equal(want.Close) {
+					t.Errorf("candle %d = %+v, want %+v", i, c, want)
+				}
+				prev = want.Close
+			}
+		})
+	}
+}
+
+func TestIndicatorPointsCap(t *testing.T) {
+	s := NewCryptocurrencyService(slog.New(slog.NewTextHandler(io.Discard, nil)),
+		fakeCurrencies{}, nil, nil, nil, nil, nil, nil, "USDT")
+	to := candleStart.Add(maxIndicatorPoints * time.Minute)
+
+	_, err := s.Indicator(context.Background(), "BTC", IndicatorSMA, 3, time.Minute, candleStart, to.Add(time.Minute), "")
+	if !errors.Is(err, common.ErrWindowTooLarge) {
+		t.Errorf("got error %v over the cap, want %v", err, common.ErrWindowTooLarge)
+	}
+
+	// A window at the cap is computed, here failing on the unknown symbol.
+	_, err = s.Indicator(context.Background(), "BTC", IndicatorSMA, 3, time.Minute, candleStart, to, "")
+	if !errors.Is(err, common.ErrCryptocurrencyNotFound) {
+		t.Errorf("got error %v at the cap, want %v", err, common.ErrCryptocurrencyNotFound)
+	}
+}
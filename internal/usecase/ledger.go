@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+// averageCostPlaces is the number of decimal places of the cost of
+// quantities sold at average cost.
+const averageCostPlaces = 16
+
+type lot struct {
+	quantity decimal.Decimal
+	price    decimal.Decimal
+}
+
+// book tracks holdings of one cryptocurrency.
+type book struct {
+	cryptocurrencyID int
+	symbol           string
+	lots             []lot
+	quantity         decimal.Decimal
+	cost             decimal.Decimal
+	realized         decimal.Decimal
+}
+
+// ledger replays trades with FIFO or average-cost accounting.
+type ledger struct {
+	accounting string
+	trades     []entity.Trade
+	next       int
+	books      []*book
+	index      map[int]*book
+}
+
+// newLedger creates the ledger of trades ordered by timestamp.
+func newLedger(accounting string, trades []entity.Trade) *ledger {
+	return &ledger{
+		accounting: accounting,
+		trades:     trades,
+		index:      make(map[int]*book),
+	}
+}
+
+// replay applies trades, ordered by timestamp, made up to at.
+func replay(accounting string, trades []entity.Trade, at time.Time) (*ledger, error) {
+	l := newLedger(accounting, trades)
+	if err := l.advance(at); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// advance applies trades made up to at which are not applied yet, so
+// holdings at increasing times are replayed once.
+func (l *ledger) advance(at time.Time) error {
+	for ; l.next < len(l.trades) && !l.trades[l.next].Timestamp.After(at); l.next++ {
+		if err := l.apply(l.trades[l.next]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snapshot returns copies of books without their lots.
+func (l *ledger) snapshot() []book {
+	books := make([]book, 0, len(l.books))
+	for _, b := range l.books {
+		cp := *b
+		cp.lots = nil
+		books = append(books, cp)
+	}
+
+	return books
+}
+
+func (l *ledger) apply(t entity.Trade) error {
+	b, ok := l.index[t.CryptocurrencyID]
+	if !ok {
+		b = &book{cryptocurrencyID: t.CryptocurrencyID, symbol: t.Symbol}
+		l.index[t.CryptocurrencyID] = b
+		l.books = append(l.books, b)
+	}
+
+	if t.Quantity.IsPositive() {
+		b.buy(t.Quantity, t.Price)
+		return nil
+	}
+
+	qty := t.Quantity.Neg()
+	if qty.GreaterThan(b.quantity) {
+		return common.ErrInsufficientQuantity
+	}
+
+	if l.accounting == entity.AccountingAverage {
+		b.sellAverage(qty, t.Price)
+	} else {
+		b.sellFIFO(qty, t.Price)
+	}
+
+	return nil
+}
+
+func (b *book) buy(qty, price decimal.Decimal) {
+	b.lots = append(b.lots, lot{quantity: qty, price: price})
+	b.quantity = b.quantity.Add(qty)
+	b.cost = b.cost.Add(qty.Mul(price))
+}
+
+func (b *book) sellFIFO(qty, price decimal.Decimal) {
+	b.quantity = b.quantity.Sub(qty)
+	for qty.IsPositive() {
+		head := &b.lots[0]
+		used := decimal.Min(qty, head.quantity)
+
+		b.realized = b.realized.Add(price.Sub(head.price).Mul(used))
+		b.cost = b.cost.Sub(head.price.Mul(used))
+		head.quantity = head.quantity.Sub(used)
+		qty = qty.Sub(used)
+
+		if head.quantity.IsZero() {
+			b.lots = b.lots[1:]
+		}
+	}
+}
+
+// sellAverage sells at the average cost. The cost of the sold quantity is
+// prorated from the cost of holdings, so a full close leaves no cost.
+func (b *book) sellAverage(qty, price decimal.Decimal) {
+	sold := b.cost
+	if qty.LessThan(b.quantity) {
+		sold = b.cost.Mul(qty).DivRound(b.quantity, averageCostPlaces)
+	}
+
+	b.realized = b.realized.Add(price.Mul(qty).Sub(sold))
+	b.quantity = b.quantity.Sub(qty)
+	b.cost = b.cost.Sub(sold)
+	if b.quantity.IsZero() {
+		b.cost = decimal.Zero
+		b.lots = nil
+	}
+}
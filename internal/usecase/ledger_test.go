@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+var tradeStart = time.Unix(1754578944, 0)
+
+// trades returns trades of quantity and price pairs of the cryptocurrency a
+// minute apart. Negative quantities are sells.
+func trades(cryptocurrencyID int, pairs ...string) []entity.Trade {
+	out := make([]entity.Trade, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		out = append(out, entity.Trade{
+			CryptocurrencyID: cryptocurrencyID,
+			Quantity:         decimal.RequireFromString(pairs[i]),
+			Price:            decimal.RequireFromString(pairs[i+1]),
+			Timestamp:        tradeStart.Add(time.Duration(i/2) * time.Minute),
+		})
+	}
+	return out
+}
+
+type wantBook struct {
+	quantity, cost, realized string
+}
+
+func assertBook(t *testing.T, b *book, want wantBook) {
+	t.Helper()
+
+	for _, f := range []struct {
+		name string
+		got  decimal.Decimal
+		want string
+	}{
+		{name: "quantity", got: b.quantity, want: want.quantity},
+		{name: "cost", got: b.cost, want: want.cost},
+		{name: "realized", got: b.realized, want: want.realized},
+	} {
+		if !f.got.Equal(decimal.RequireFromString(f.want)) {
+			t.Errorf("%s = %s, want %s", f.name, f.got, f.want)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name       string
+		accounting string
+		trades     []entity.Trade
+		want       wantBook
+		err        error
+	}{
+		{
+			name:       "fifo partial sell across lots",
+			accounting: entity.AccountingFIFO,
+			trades:     trades(1, "2", "10", "2", "20", "-3", "30"),
+			want:       wantBook{quantity: "1", cost: "20", realized: "50"},
+		},
+		{
+			name:       "fifo full close",
+			accounting: entity.AccountingFIFO,
+			trades:     trades(1, "1", "10", "1", "20", "-2", "15"),
+			want:       wantBook{quantity: "0", cost: "0", realized: "0"},
+		},
+		{
+			name:       "fifo sell beyond holdings",
+			accounting: entity.AccountingFIFO,
+			trades:     trades(1, "1", "10", "-1.5", "20"),
+			err:        common.ErrInsufficientQuantity,
+		},
+		{
+			name:       "average partial sell",
+			accounting: entity.AccountingAverage,
+			trades:     trades(1, "2", "10", "1", "40", "-1", "50"),
+			want:       wantBook{quantity: "2", cost: "40", realized: "30"},
+		},
+		{
+			// The average cost of 1/3 is rounded, the full close still
+			// realizes proceeds less the whole cost.
+			name:       "average full close leaves zero cost",
+			accounting: entity.AccountingAverage,
+			trades:     trades(1, "1", "1", "2", "0", "-1", "1", "-2", "1"),
+			want:       wantBook{quantity: "0", cost: "0", realized: "2"},
+		},
+		{
+			name:       "average sell beyond holdings",
+			accounting: entity.AccountingAverage,
+			trades:     trades(1, "1", "10", "-2", "20"),
+			err:        common.ErrInsufficientQuantity,
+		},
+		{
+			name:       "sell without holdings",
+			accounting: entity.AccountingFIFO,
+			trades:     trades(1, "-1", "10"),
+			err:        common.ErrInsufficientQuantity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := tt.trades[len(tt.trades)-1].Timestamp
+			l, err := replay(tt.accounting, tt.trades, at)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got error %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertBook(t, l.index[1], tt.want)
+		})
+	}
+}
+
+func TestLedgerAdvance(t *testing.T) {
+	all := append(trades(1, "2", "10", "-1", "20"), trades(2, "1", "5")...)
+	all[2].Timestamp = tradeStart.Add(90 * time.Second)
+
+	l := newLedger(entity.AccountingFIFO, all)
+	if err := l.advance(tradeStart); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.books) != 1 {
+		t.Fatalf("got %d books before later trades, want 1", len(l.books))
+	}
+	assertBook(t, l.index[1], wantBook{quantity: "2", cost: "20", realized: "0"})
+
+	// Trades already applied are not applied again.
+	for _, at := range []time.Time{tradeStart.Add(time.Minute), tradeStart.Add(2 * time.Minute)} {
+		if err := l.advance(at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertBook(t, l.index[1], wantBook{quantity: "1", cost: "10", realized: "10"})
+	assertBook(t, l.index[2], wantBook{quantity: "1", cost: "5", realized: "0"})
+
+	books := l.snapshot()
+	books[0].quantity = decimal.Zero
+	if l.index[1].quantity.IsZero() {
+		t.Error("snapshot shares books with the ledger")
+	}
+}
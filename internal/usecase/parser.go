@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+)
+
+// ParserControl reports and steers the price parser of this replica.
+type ParserControl interface {
+	Status() entity.ParserStatus
+	Pause(symbol string) error
+	Resume(symbol string) error
+	PauseAll() error
+	ResumeAll() error
+	Poll(ctx context.Context, symbol string) error
+	SetWorkers(n int)
+}
+
+type ParserService struct {
+	log    *slog.Logger
+	parser ParserControl
+}
+
+func NewParserService(log *slog.Logger, parser ParserControl) *ParserService {
+	return &ParserService{
+		log:    log,
+		parser: parser,
+	}
+}
+
+func (s *ParserService) Status(ctx context.Context) entity.ParserStatus {
+	return s.parser.Status()
+}
+
+// Pause stops polling of the coin, or of all coins when symbol is empty,
+// without changing its tracking.
+func (s *ParserService) Pause(ctx context.Context, symbol string) error {
+	const op = "ParserService.Pause"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("symbol", symbol))
+
+	log.Debug("trying to pause parser")
+	if symbol == "" {
+		if err := s.parser.PauseAll(); err != nil {
+			log.Error(fmt.Sprintf("fail to pause parser! Error: %s", err))
+			return err
+		}
+		log.Info("paused parser")
+		return nil
+	}
+
+	if err := s.parser.Pause(symbol); err != nil {
+		log.Error(fmt.Sprintf("fail to pause coin! Error: %s", err))
+		return err
+	}
+	log.Info("paused coin")
+
+	return nil
+}
+
+// Resume continues polling of the coin, or of all coins when symbol is empty.
+func (s *ParserService) Resume(ctx context.Context, symbol string) error {
+	const op = "ParserService.Resume"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("symbol", symbol))
+
+	log.Debug("trying to resume parser")
+	if symbol == "" {
+		if err := s.parser.ResumeAll(); err != nil {
+			log.Error(fmt.Sprintf("fail to resume parser! Error: %s", err))
+			return err
+		}
+		log.Info("resumed parser")
+		return nil
+	}
+
+	if err := s.parser.Resume(symbol); err != nil {
+		log.Error(fmt.Sprintf("fail to resume coin! Error: %s", err))
+		return err
+	}
+	log.Info("resumed coin")
+
+	return nil
+}
+
+// Poll queues an immediate poll of the coin.
+func (s *ParserService) Poll(ctx context.Context, symbol string) error {
+	const op = "ParserService.Poll"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("symbol", symbol))
+
+	log.Debug("trying to queue poll")
+	if err := s.parser.Poll(ctx, symbol); err != nil {
+		log.Error(fmt.Sprintf("fail to queue poll! Error: %s", err))
+		return err
+	}
+	log.Debug("successfully queued poll")
+
+	return nil
+}
+
+// Resize changes the number of parser workers until the next restart or
+// change of PARSER_WORKERS.
+func (s *ParserService) Resize(ctx context.Context, workers int) {
+	const op = "ParserService.Resize"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Int("workers", workers))
+
+	s.parser.SetWorkers(workers)
+	log.Info("resized parser workers")
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+)
+
+type PartitionStorage interface {
+	GetMonths(ctx context.Context) ([]time.Time, error)
+	Create(ctx context.Context, month time.Time) error
+	Detach(ctx context.Context, month time.Time, keep bool) error
+}
+
+// PartitionService maintains monthly partitions of price history.
+type PartitionService struct {
+	log        *slog.Logger
+	pst        PartitionStorage
+	premake    int
+	retention  int
+	detachOnly bool
+}
+
+// NewPartitionService creates the service keeping premake months of
+// partitions ahead and removing partitions older than retention months.
+// Zero retention keeps partitions forever; detachOnly keeps removed
+// partitions as standalone tables.
+func NewPartitionService(
+	log *slog.Logger,
+	pst PartitionStorage,
+	premake, retention int,
+	detachOnly bool,
+) *PartitionService {
+	return &PartitionService{
+		log:        log,
+		pst:        pst,
+		premake:    premake,
+		retention:  retention,
+		detachOnly: detachOnly,
+	}
+}
+
+// Maintain creates missing partitions from the latest existing one up to
+// premake months ahead and detaches partitions past retention.
+func (s *PartitionService) Maintain(ctx context.Context) error {
+	const op = "PartitionService.Maintain"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op))
+
+	log.Debug("trying to get partitions")
+	months, err := s.pst.GetMonths(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get partitions! Error: %s", err))
+		return err
+	}
+
+	now := time.Now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// Fill gaps left while the service was down, so late samples have a
+	// partition. Months before the latest partition are covered already,
+	// by monthly partitions or the legacy one.
+	start := current
+	if len(months) > 0 {
+		start = months[len(months)-1].AddDate(0, 1, 0)
+	}
+
+	for m := start; !m.After(current.AddDate(0, s.premake, 0)); m = m.AddDate(0, 1, 0) {
+		if err := s.pst.Create(ctx, m); err != nil {
+			log.Error(fmt.Sprintf("fail to create partition for %s! Error: %s", m.Format("2006-01"), err))
+			return err
+		}
+		log.Info(fmt.Sprintf("created partition for %s", m.Format("2006-01")))
+	}
+
+	if s.retention <= 0 {
+		return nil
+	}
+
+	cutoff := current.AddDate(0, -s.retention, 0)
+	for _, m := range months {
+		if !m.Before(cutoff) {
+			break
+		}
+
+		if err := s.pst.Detach(ctx, m, s.detachOnly); err != nil {
+			log.Error(fmt.Sprintf("fail to detach partition for %s! Error: %s", m.Format("2006-01"), err))
+			return err
+		}
+		log.Info(fmt.Sprintf("detached partition for %s", m.Format("2006-01")))
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/internal/common"
+	"github.com/Homyakadze14/AFFARM_tz/internal/entity"
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+)
+
+const maxPortfolioPoints = 1000
+
+type PortfolioStorage interface {
+	Create(ctx context.Context, p *entity.Portfolio) (*entity.Portfolio, error)
+	GetByID(ctx context.Context, id int) (*entity.Portfolio, error)
+	Lock(ctx context.Context, id int) error
+	AddTrade(ctx context.Context, t *entity.Trade) (*entity.Trade, error)
+	GetTrades(ctx context.Context, portfolioID int) ([]entity.Trade, error)
+}
+
+type PortfolioService struct {
+	log *slog.Logger
+	pst PortfolioStorage
+	cst CryptocurrencyStorage
+	hst HistoryStorage
+	tm  TxManager
+}
+
+func NewPortfolioService(
+	log *slog.Logger,
+	pst PortfolioStorage,
+	cst CryptocurrencyStorage,
+	hst HistoryStorage,
+	tm TxManager,
+) *PortfolioService {
+	return &PortfolioService{
+		log: log,
+		pst: pst,
+		cst: cst,
+		hst: hst,
+		tm:  tm,
+	}
+}
+
+func (s *PortfolioService) Create(ctx context.Context, p *entity.Portfolio) (*entity.Portfolio, error) {
+	const op = "PortfolioService.Create"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.String("name", p.Name))
+
+	log.Debug("trying to create portfolio")
+	p, err := s.pst.Create(ctx, p)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to create portfolio! Error: %s", err))
+		return nil, err
+	}
+	log.Debug("successfully created portfolio")
+
+	return p, nil
+}
+
+// AddTrade records a trade after checking that the portfolio never sells
+// more than it holds once the trade is placed in its timestamp order. The
+// portfolio is locked meanwhile, so concurrent trades are checked in turn.
+func (s *PortfolioService) AddTrade(ctx context.Context, t *entity.Trade) (*entity.Trade, error) {
+	const op = "PortfolioService.AddTrade"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Int("portfolio_id", t.PortfolioID),
+		slog.String("symbol", t.Symbol))
+
+	log.Debug("trying to add trade")
+	cr, err := s.cst.GetBySymbol(ctx, t.Symbol)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get cryptocurrency by symbol! Error: %s", err))
+		return nil, err
+	}
+	t.CryptocurrencyID = cr.ID
+
+	err = s.tm.Do(ctx, func(ctx context.Context) error {
+		if err := s.pst.Lock(ctx, t.PortfolioID); err != nil {
+			log.Error(fmt.Sprintf("fail to lock portfolio! Error: %s", err))
+			return err
+		}
+
+		p, err := s.pst.GetByID(ctx, t.PortfolioID)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get portfolio! Error: %s", err))
+			return err
+		}
+
+		trades, err := s.pst.GetTrades(ctx, p.ID)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get trades! Error: %s", err))
+			return err
+		}
+
+		pos := len(trades)
+		for i, tr := range trades {
+			if t.Timestamp.Before(tr.Timestamp) {
+				pos = i
+				break
+			}
+		}
+		trades = append(trades[:pos], append([]entity.Trade{*t}, trades[pos:]...)...)
+
+		_, err = replay(p.Accounting, trades, trades[len(trades)-1].Timestamp)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to replay trades! Error: %s", err))
+			return err
+		}
+
+		_, err = s.pst.AddTrade(ctx, t)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to add trade! Error: %s", err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("successfully added trade")
+
+	return t, nil
+}
+
+// Value values the portfolio at the given time using the nearest stored
+// prices, which must lie within DefaultConvertTolerance like in conversions.
+func (s *PortfolioService) Value(ctx context.Context, portfolioID int, at time.Time) (*entity.PortfolioValuation, error) {
+	const op = "PortfolioService.Value"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Int("portfolio_id", portfolioID),
+		slog.Time("at", at))
+
+	log.Debug("trying to value portfolio")
+	p, err := s.pst.GetByID(ctx, portfolioID)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get portfolio! Error: %s", err))
+		return nil, err
+	}
+
+	trades, err := s.pst.GetTrades(ctx, p.ID)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get trades! Error: %s", err))
+		return nil, err
+	}
+
+	val, err := s.value(ctx, p, trades, at)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to value portfolio! Error: %s", err))
+		return nil, err
+	}
+	log.Debug("successfully valued portfolio")
+
+	return val, nil
+}
+
+// History values the portfolio at every step in [from, to] with one price
+// query per held cryptocurrency.
+func (s *PortfolioService) History(
+	ctx context.Context,
+	portfolioID int,
+	from, to time.Time,
+	step time.Duration,
+) ([]entity.PortfolioValuation, error) {
+	const op = "PortfolioService.History"
+	log := logctx.From(ctx, s.log).With(slog.String("op", op),
+		slog.Int("portfolio_id", portfolioID),
+		slog.Time("from", from),
+		slog.Time("to", to),
+		slog.Duration("step", step))
+
+	log.Debug("trying to get portfolio value history")
+	if int64(to.Sub(from)/step) > maxPortfolioPoints {
+		log.Error("too many points in window!")
+		return nil, common.ErrWindowTooLarge
+	}
+
+	p, err := s.pst.GetByID(ctx, portfolioID)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get portfolio! Error: %s", err))
+		return nil, err
+	}
+
+	trades, err := s.pst.GetTrades(ctx, p.ID)
+	if err != nil {
+		log.Error(fmt.Sprintf("fail to get trades! Error: %s", err))
+		return nil, err
+	}
+
+	// Trades are replayed once across steps, then prices of each held coin
+	// are looked up at all steps it is held in one query.
+	l := newLedger(p.Accounting, trades)
+	steps := make([]time.Time, 0, int(to.Sub(from)/step)+1)
+	snapshots := make([][]book, 0, cap(steps))
+	held := make(map[int][]time.Time)
+	for at := from; !at.After(to); at = at.Add(step) {
+		if err := l.advance(at); err != nil {
+			log.Error(fmt.Sprintf("fail to replay trades! Error: %s", err))
+			return nil, err
+		}
+
+		books := l.snapshot()
+		for _, b := range books {
+			if !b.quantity.IsZero() {
+				held[b.cryptocurrencyID] = append(held[b.cryptocurrencyID], at)
+			}
+		}
+		steps = append(steps, at)
+		snapshots = append(snapshots, books)
+	}
+
+	prices := make(map[sampleKey]*entity.PriceHistory)
+	for id, times := range held {
+		hists, err := s.hst.GetNearestPrices(ctx, id, times)
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to get prices! Error: %s", err))
+			return nil, err
+		}
+		for i, h := range hists {
+			prices[sampleKey{cryptocurrencyID: id, timestamp: times[i].UnixMicro()}] = h
+		}
+	}
+
+	vals := make([]entity.PortfolioValuation, 0, len(steps))
+	for i, at := range steps {
+		val, err := valuate(p, at, snapshots[i], func(b *book) (*entity.PriceHistory, error) {
+			h := prices[sampleKey{cryptocurrencyID: b.cryptocurrencyID, timestamp: at.UnixMicro()}]
+			if h == nil {
+				return nil, common.ErrHistoryNotFound
+			}
+			return h, nil
+		})
+		if err != nil {
+			log.Error(fmt.Sprintf("fail to value portfolio! Error: %s", err))
+			return nil, err
+		}
+		val.Positions = nil
+		vals = append(vals, *val)
+	}
+	log.Debug("successfully got portfolio value history")
+
+	return vals, nil
+}
+
+// value values trades made up to at.
+func (s *PortfolioService) value(
+	ctx context.Context,
+	p *entity.Portfolio,
+	trades []entity.Trade,
+	at time.Time,
+) (*entity.PortfolioValuation, error) {
+	l, err := replay(p.Accounting, trades, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return valuate(p, at, l.snapshot(), func(b *book) (*entity.PriceHistory, error) {
+		return s.hst.GetNearestPrice(ctx, b.cryptocurrencyID, at)
+	})
+}
+
+// valuate values holdings of books at at. price returns the sample nearest
+// to at of a held cryptocurrency.
+func valuate(
+	p *entity.Portfolio,
+	at time.Time,
+	books []book,
+	price func(b *book) (*entity.PriceHistory, error),
+) (*entity.PortfolioValuation, error) {
+	val := &entity.PortfolioValuation{
+		PortfolioID: p.ID,
+		Timestamp:   at,
+		Positions:   make([]entity.Position, 0, len(books)),
+	}
+	for i := range books {
+		b := &books[i]
+		pos := entity.Position{
+			Symbol:      b.symbol,
+			Quantity:    b.quantity,
+			CostBasis:   b.cost,
+			RealizedPnL: b.realized,
+		}
+
+		if !b.quantity.IsZero() {
+			hist, err := price(b)
+			if err != nil {
+				return nil, err
+			}
+			if skew := hist.Timestamp.Sub(at).Abs(); skew > DefaultConvertTolerance {
+				return nil, fmt.Errorf("%s is %s away from requested time: %w", b.symbol, skew, common.ErrPriceOutOfTolerance)
+			}
+			pos.Price = hist.Price
+			pos.PriceAt = hist.Timestamp
+			pos.Value = b.quantity.Mul(hist.Price)
+			pos.UnrealizedPnL = pos.Value.Sub(b.cost)
+		}
+
+		val.Value = val.Value.Add(pos.Value)
+		val.CostBasis = val.CostBasis.Add(pos.CostBasis)
+		val.RealizedPnL = val.RealizedPnL.Add(pos.RealizedPnL)
+		val.UnrealizedPnL = val.UnrealizedPnL.Add(pos.UnrealizedPnL)
+		val.Positions = append(val.Positions, pos)
+	}
+
+	return val, nil
+}
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS fx_rates (
+    currency VARCHAR(10) NOT NULL,
+    date DATE NOT NULL,
+    rate NUMERIC(20, 8) NOT NULL,
+    PRIMARY KEY (currency, date)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS portfolio_trades;
+DROP TABLE IF EXISTS portfolios;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS portfolios (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    accounting VARCHAR(10) NOT NULL DEFAULT 'fifo',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS portfolio_trades (
+    id BIGSERIAL PRIMARY KEY,
+    portfolio_id INT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    cryptocurrency_id INT NOT NULL REFERENCES cryptocurrencies(id) ON DELETE CASCADE,
+    quantity NUMERIC(28, 8) NOT NULL,
+    price NUMERIC(20, 8) NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_trades ON portfolio_trades (portfolio_id, timestamp);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS price_archives (
+    id SERIAL PRIMARY KEY,
+    cryptocurrency_id INT NOT NULL REFERENCES cryptocurrencies(id) ON DELETE CASCADE,
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    object_key TEXT NOT NULL,
+    rows BIGINT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (cryptocurrency_id, period_start)
+);
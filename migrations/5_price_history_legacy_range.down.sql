@@ -0,0 +1 @@
+ALTER TABLE price_history DROP CONSTRAINT IF EXISTS price_history_legacy_range;
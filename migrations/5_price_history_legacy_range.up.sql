@@ -0,0 +1,10 @@
+-- First step of partitioning price_history, see 7_partition_price_history.
+-- Adding the constraint as NOT VALID takes the exclusive lock only briefly;
+-- it is validated by the next migration without blocking writes.
+DO $$
+BEGIN
+    EXECUTE format(
+        'ALTER TABLE price_history ADD CONSTRAINT price_history_legacy_range
+         CHECK (timestamp IS NOT NULL AND timestamp < %L) NOT VALID',
+        date_trunc('month', now(), 'UTC') + interval '1 month');
+END $$;
@@ -0,0 +1 @@
+-- Validation is undone together with the constraint by 5_price_history_legacy_range.
@@ -0,0 +1,3 @@
+-- Scans price_history in its own transaction holding only a SHARE UPDATE
+-- EXCLUSIVE lock, so samples keep being written meanwhile.
+ALTER TABLE price_history VALIDATE CONSTRAINT price_history_legacy_range;
@@ -0,0 +1,16 @@
+CREATE TABLE price_history_plain (
+    id BIGINT NOT NULL DEFAULT nextval('price_history_id_seq') PRIMARY KEY,
+    cryptocurrency_id INT NOT NULL REFERENCES cryptocurrencies(id) ON DELETE CASCADE,
+    price NUMERIC(20, 8) NOT NULL,
+    timestamp TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO price_history_plain (id, cryptocurrency_id, price, timestamp)
+SELECT id, cryptocurrency_id, price, timestamp FROM price_history;
+
+ALTER SEQUENCE price_history_id_seq OWNED BY price_history_plain.id;
+DROP TABLE price_history;
+ALTER TABLE price_history_plain RENAME TO price_history;
+ALTER INDEX price_history_plain_pkey RENAME TO price_history_pkey;
+
+CREATE INDEX IF NOT EXISTS idx_price_history ON price_history (cryptocurrency_id, timestamp DESC);
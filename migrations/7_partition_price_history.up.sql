@@ -0,0 +1,42 @@
+-- Converts price_history to monthly range partitions without copying data.
+-- The existing table, validated against a range ending next month by the
+-- previous migrations, becomes price_history_legacy, the partition of all
+-- samples up to the end of the current month. Setting NOT NULL and attaching
+-- use the validated constraint instead of scanning the table, so the
+-- exclusive lock is short. Retention only expires monthly partitions, so the
+-- legacy one is kept until dropped by hand. Later months get their own
+-- partitions which the partition maintenance job pre-creates.
+DO $$
+DECLARE
+    boundary TIMESTAMPTZ := date_trunc('month', now(), 'UTC') + interval '1 month';
+    legacy TEXT := 'price_history_legacy';
+    part_start TIMESTAMPTZ;
+BEGIN
+    ALTER TABLE price_history ALTER COLUMN timestamp SET NOT NULL;
+
+    EXECUTE format('ALTER TABLE price_history RENAME TO %I', legacy);
+    EXECUTE format('ALTER INDEX idx_price_history RENAME TO %I', 'idx_' || legacy);
+
+    CREATE TABLE price_history (
+        id BIGINT NOT NULL DEFAULT nextval('price_history_id_seq'),
+        cryptocurrency_id INT NOT NULL REFERENCES cryptocurrencies(id) ON DELETE CASCADE,
+        price NUMERIC(20, 8) NOT NULL,
+        timestamp TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ) PARTITION BY RANGE (timestamp);
+    ALTER SEQUENCE price_history_id_seq OWNED BY price_history.id;
+
+    CREATE INDEX idx_price_history ON price_history (cryptocurrency_id, timestamp DESC);
+
+    EXECUTE format(
+        'ALTER TABLE price_history ATTACH PARTITION %I FOR VALUES FROM (MINVALUE) TO (%L)',
+        legacy, boundary);
+    EXECUTE format('ALTER TABLE %I DROP CONSTRAINT price_history_legacy_range', legacy);
+
+    FOR i IN 0..2 LOOP
+        part_start := boundary + make_interval(months => i);
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF price_history FOR VALUES FROM (%L) TO (%L)',
+            'price_history_' || to_char(part_start AT TIME ZONE 'UTC', 'YYYY_MM'),
+            part_start, part_start + interval '1 month');
+    END LOOP;
+END $$;
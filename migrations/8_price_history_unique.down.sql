@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_price_history ON price_history (cryptocurrency_id, timestamp DESC);
+
+DROP INDEX IF EXISTS price_history_cryptocurrency_timestamp_key;
@@ -0,0 +1,30 @@
+-- Keeps the latest written sample of each (cryptocurrency_id, timestamp)
+-- pair. The unique index serves the same lookups as the old index. It is
+-- created on the partitioned table only, then duplicates are removed and the
+-- index is built and attached partition by partition, so each step scans a
+-- single partition instead of joining and indexing the whole table at once.
+-- Right after partitioning only price_history_legacy holds samples. Later
+-- partitions get the index from the partitioned table.
+DO $$
+DECLARE
+    part REGCLASS;
+    idx TEXT;
+BEGIN
+    CREATE UNIQUE INDEX price_history_cryptocurrency_timestamp_key
+        ON ONLY price_history (cryptocurrency_id, timestamp);
+
+    FOR part IN SELECT inhrelid::regclass FROM pg_inherits WHERE inhparent = 'price_history'::regclass LOOP
+        idx := (SELECT relname FROM pg_class WHERE oid = part) || '_cryptocurrency_timestamp_key';
+
+        EXECUTE format(
+            'DELETE FROM %s AS a USING %s AS b
+             WHERE a.cryptocurrency_id = b.cryptocurrency_id
+               AND a.timestamp = b.timestamp
+               AND a.id < b.id',
+            part, part);
+        EXECUTE format('CREATE UNIQUE INDEX %I ON %s (cryptocurrency_id, timestamp)', idx, part);
+        EXECUTE format('ALTER INDEX price_history_cryptocurrency_timestamp_key ATTACH PARTITION %I', idx);
+    END LOOP;
+END $$;
+
+DROP INDEX IF EXISTS idx_price_history;
@@ -0,0 +1,3 @@
+ALTER TABLE trackings
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS poll_interval_ms;
@@ -0,0 +1,3 @@
+ALTER TABLE trackings
+    ADD COLUMN IF NOT EXISTS poll_interval_ms BIGINT CHECK (poll_interval_ms > 0),
+    ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
@@ -0,0 +1,141 @@
+// Package correlation aligns price series on a common time grid and computes
+// correlation matrices of their returns.
+package correlation
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	Pearson  = "pearson"
+	Spearman = "spearman"
+)
+
+// ForwardFill places each series on the grid, carrying the last known value
+// into empty slots. Slots before the first value of a series are NaN.
+func ForwardFill(grid []int64, series []map[int64]float64) [][]float64 {
+	out := make([][]float64, len(series))
+	for i, s := range series {
+		filled := make([]float64, len(grid))
+		last := math.NaN()
+		for j, t := range grid {
+			if v, ok := s[t]; ok {
+				last = v
+			}
+			filled[j] = last
+		}
+		out[i] = filled
+	}
+
+	return out
+}
+
+// Returns converts aligned price series into simple returns. Only grid steps
+// where every series has both the previous and current value are kept, so
+// the result stays aligned across series.
+func Returns(aligned [][]float64) [][]float64 {
+	out := make([][]float64, len(aligned))
+	if len(aligned) == 0 {
+		return out
+	}
+
+	for j := 1; j < len(aligned[0]); j++ {
+		ok := true
+		for _, s := range aligned {
+			if math.IsNaN(s[j-1]) || math.IsNaN(s[j]) || s[j-1] == 0 {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		for i, s := range aligned {
+			out[i] = append(out[i], s[j]/s[j-1]-1)
+		}
+	}
+
+	return out
+}
+
+// Matrix returns the symmetric correlation matrix of the series using method.
+// Undefined coefficients, e.g. for a constant series, are NaN.
+func Matrix(series [][]float64, method string) [][]float64 {
+	if method == Spearman {
+		ranked := make([][]float64, len(series))
+		for i, s := range series {
+			ranked[i] = ranks(s)
+		}
+		series = ranked
+	}
+
+	m := make([][]float64, len(series))
+	for i := range m {
+		m[i] = make([]float64, len(series))
+	}
+
+	for i := range series {
+		for j := i; j < len(series); j++ {
+			c := pearson(series[i], series[j])
+			m[i][j] = c
+			m[j][i] = c
+		}
+	}
+
+	return m
+}
+
+func pearson(x, y []float64) float64 {
+	n := len(x)
+	if n < 2 || n != len(y) {
+		return math.NaN()
+	}
+
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(n)
+	my /= float64(n)
+
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+
+	if vx == 0 || vy == 0 {
+		return math.NaN()
+	}
+
+	return cov / math.Sqrt(vx*vy)
+}
+
+// ranks returns fractional ranks with ties sharing their average rank.
+func ranks(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	out := make([]float64, len(values))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && values[idx[j+1]] == values[idx[i]] {
+			j++
+		}
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			out[idx[k]] = rank
+		}
+		i = j + 1
+	}
+
+	return out
+}
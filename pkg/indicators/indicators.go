@@ -0,0 +1,127 @@
+// Package indicators computes technical indicators over a series of closing prices.
+//
+// Every function returns a series aligned with its input: values that can't be
+// computed yet because of the warm-up period are NaN.
+package indicators
+
+import "math"
+
+// SMA is the simple moving average over period values.
+func SMA(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return out
+	}
+
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+
+	return out
+}
+
+// EMA is the exponential moving average seeded with the SMA of the first period values.
+func EMA(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return out
+	}
+
+	alpha := 2 / float64(period+1)
+
+	var seed float64
+	for _, v := range values[:period] {
+		seed += v
+	}
+	out[period-1] = seed / float64(period)
+
+	for i := period; i < len(values); i++ {
+		out[i] = alpha*values[i] + (1-alpha)*out[i-1]
+	}
+
+	return out
+}
+
+// RSI is the relative strength index using Wilder's smoothing.
+func RSI(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	if period <= 0 || len(values) <= period {
+		return out
+	}
+
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		g, l := change(values[i-1], values[i])
+		gain += g
+		loss += l
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	out[period] = rsi(gain, loss)
+
+	for i := period + 1; i < len(values); i++ {
+		g, l := change(values[i-1], values[i])
+		gain = (gain*float64(period-1) + g) / float64(period)
+		loss = (loss*float64(period-1) + l) / float64(period)
+		out[i] = rsi(gain, loss)
+	}
+
+	return out
+}
+
+// Bollinger returns the middle (SMA), upper and lower bands at k population
+// standard deviations.
+func Bollinger(values []float64, period int, k float64) (middle, upper, lower []float64) {
+	middle = SMA(values, period)
+	upper = nanSeries(len(values))
+	lower = nanSeries(len(values))
+
+	for i := range values {
+		if math.IsNaN(middle[i]) {
+			continue
+		}
+
+		var variance float64
+		for _, v := range values[i-period+1 : i+1] {
+			variance += (v - middle[i]) * (v - middle[i])
+		}
+		sd := math.Sqrt(variance / float64(period))
+
+		upper[i] = middle[i] + k*sd
+		lower[i] = middle[i] - k*sd
+	}
+
+	return middle, upper, lower
+}
+
+func change(prev, cur float64) (gain, loss float64) {
+	if cur > prev {
+		return cur - prev, 0
+	}
+	return 0, prev - cur
+}
+
+func rsi(gain, loss float64) float64 {
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// closes10 is the 10-day moving average example of StockCharts ChartSchool.
+var closes10 = []float64{
+	22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+	22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+}
+
+// closes14 is the 14-day RSI example of StockCharts ChartSchool. Its table
+// rounds average gains and losses, so its RSI differs by up to 0.1.
+var closes14 = []float64{
+	44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+	45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	46.21, 46.25, 45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57,
+	43.42, 42.66, 43.13,
+}
+
+// warmup prefixes want with n NaN values of the warm-up period.
+func warmup(n int, want ...float64) []float64 {
+	return append(nanSeries(n), want...)
+}
+
+func assertSeries(t *testing.T, got, want []float64, tolerance float64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d values, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if math.IsNaN(want[i]) {
+			if !math.IsNaN(got[i]) {
+				t.Errorf("value %d = %.4f, want NaN", i, got[i])
+			}
+			continue
+		}
+		if math.Abs(got[i]-want[i]) > tolerance {
+			t.Errorf("value %d = %.4f, want %.4f", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{
+			name:   "stockcharts 10 day",
+			values: closes10,
+			period: 10,
+			want: warmup(9,
+				22.22, 22.21, 22.23, 22.26, 22.31, 22.42, 22.61, 22.77, 22.91, 23.08, 23.21,
+				23.38, 23.53, 23.65, 23.71, 23.69, 23.61, 23.51, 23.43, 23.28, 23.13),
+		},
+		{
+			name:   "period of one",
+			values: []float64{1, 2, 3},
+			period: 1,
+			want:   []float64{1, 2, 3},
+		},
+		{
+			name:   "shorter than period",
+			values: []float64{1, 2},
+			period: 3,
+			want:   warmup(2),
+		},
+		{
+			name:   "non positive period",
+			values: []float64{1, 2},
+			period: 0,
+			want:   warmup(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSeries(t, SMA(tt.values, tt.period), tt.want, 0.01)
+		})
+	}
+}
+
+func TestEMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{
+			name:   "stockcharts 10 day",
+			values: closes10,
+			period: 10,
+			want: warmup(9,
+				22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28, 23.34,
+				23.43, 23.51, 23.54, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92),
+		},
+		{
+			name:   "constant series",
+			values: []float64{5, 5, 5, 5},
+			period: 2,
+			want:   warmup(1, 5, 5, 5),
+		},
+		{
+			name:   "shorter than period",
+			values: []float64{1, 2},
+			period: 3,
+			want:   warmup(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSeries(t, EMA(tt.values, tt.period), tt.want, 0.01)
+		})
+	}
+}
+
+func TestRSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{
+			name:   "stockcharts 14 day",
+			values: closes14,
+			period: 14,
+			want: warmup(14,
+				70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+				54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77),
+		},
+		{
+			name:   "only gains",
+			values: []float64{1, 2, 3, 4},
+			period: 2,
+			want:   warmup(2, 100, 100),
+		},
+		{
+			name:   "flat",
+			values: []float64{1, 1, 1},
+			period: 2,
+			want:   warmup(2, 50),
+		},
+		{
+			name:   "period needs one more value",
+			values: []float64{1, 2},
+			period: 2,
+			want:   warmup(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSeries(t, RSI(tt.values, tt.period), tt.want, 0.1)
+		})
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	tests := []struct {
+		name                 string
+		values               []float64
+		period               int
+		k                    float64
+		middle, upper, lower []float64
+	}{
+		{
+			// The population standard deviation example of Wikipedia: mean 5, deviation 2.
+			name:   "wikipedia standard deviation",
+			values: []float64{2, 4, 4, 4, 5, 5, 7, 9},
+			period: 8,
+			k:      2,
+			middle: warmup(7, 5),
+			upper:  warmup(7, 9),
+			lower:  warmup(7, 1),
+		},
+		{
+			name:   "rolling window",
+			values: []float64{1, 3, 5},
+			period: 2,
+			k:      1,
+			middle: warmup(1, 2, 4),
+			upper:  warmup(1, 3, 5),
+			lower:  warmup(1, 1, 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middle, upper, lower := Bollinger(tt.values, tt.period, tt.k)
+			assertSeries(t, middle, tt.middle, 1e-9)
+			assertSeries(t, upper, tt.upper, 1e-9)
+			assertSeries(t, lower, tt.lower, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Package logctx carries a request-scoped slog logger in a context, so
+// logs of every layer serving a request share its attributes.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// With returns a copy of ctx carrying log.
+func With(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// From returns the logger carried by ctx, or fallback when there is none.
+func From(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return log
+	}
+	return fallback
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Homyakadze14/AFFARM_tz/pkg/logctx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	startKey struct{}
+	queryKey struct{}
+)
+
+// queryTracer records queries, batches and copies as client spans of the
+// global tracer provider, children of the span in the query context. Queries
+// without a span in their context, e.g. of background loops, are not traced,
+// so they do not start traces of their own. Queries
+// of contexts carrying a logger, e.g. of HTTP requests, are logged at debug
+// level with it.
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+func newQueryTracer() *queryTracer {
+	return &queryTracer{tracer: otel.Tracer("github.com/Homyakadze14/AFFARM_tz/pkg/postgres")}
+}
+
+func (t *queryTracer) start(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	ctx = context.WithValue(ctx, startKey{}, time.Now())
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx
+	}
+
+	attrs = append(attrs, semconv.DBSystemNamePostgreSQL)
+	ctx, _ = t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	return ctx
+}
+
+func (t *queryTracer) end(ctx context.Context, query string, tag pgconn.CommandTag, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Int64("db.response.rows_affected", tag.RowsAffected()))
+	}
+	span.End()
+
+	log := logctx.From(ctx, nil)
+	if log == nil || !log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("query", strings.Join(strings.Fields(query), " ")),
+		slog.Int64("rows", tag.RowsAffected()),
+	}
+	if start, ok := ctx.Value(startKey{}).(time.Time); ok {
+		attrs = append(attrs, slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	log.LogAttrs(ctx, slog.LevelDebug, "postgres query", attrs...)
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx = t.start(ctx, operation(data.SQL), semconv.DBQueryText(data.SQL))
+	return context.WithValue(ctx, queryKey{}, data.SQL)
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	query, _ := ctx.Value(queryKey{}).(string)
+	t.end(ctx, query, data.CommandTag, data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer.
+func (t *queryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx, "BATCH", semconv.DBOperationBatchSize(data.Batch.Len()))
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (t *queryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("query", trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+	if data.Err != nil {
+		span.RecordError(data.Err)
+	}
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (t *queryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, "BATCH", pgconn.CommandTag{}, data.Err)
+}
+
+// TraceCopyFromStart implements pgx.CopyFromTracer.
+func (t *queryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	table := data.TableName.Sanitize()
+	ctx = t.start(ctx, "COPY "+table, semconv.DBCollectionName(table))
+	return context.WithValue(ctx, queryKey{}, "COPY "+table)
+}
+
+// TraceCopyFromEnd implements pgx.CopyFromTracer.
+func (t *queryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	query, _ := ctx.Value(queryKey{}).(string)
+	t.end(ctx, query, data.CommandTag, data.Err)
+}
+
+// operation returns the leading keyword of sql as the span name.
+func operation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,23 @@
+package tracing
+
+type Option func(*Provider)
+
+func ServiceName(name string) Option {
+	return func(p *Provider) {
+		p.serviceName = name
+	}
+}
+
+func SampleRatio(ratio float64) Option {
+	return func(p *Provider) {
+		p.sampleRatio = ratio
+	}
+}
+
+// SyncExport exports every span as it ends instead of in batches, so tests
+// with an in-memory exporter see spans right away.
+func SyncExport() Option {
+	return func(p *Provider) {
+		p.syncExport = true
+	}
+}
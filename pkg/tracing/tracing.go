@@ -0,0 +1,90 @@
+// Package tracing sets up the OpenTelemetry tracer provider of the service.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const (
+	_defaultServiceName = "affarm"
+	_defaultSampleRatio = 1.0
+)
+
+type Provider struct {
+	serviceName string
+	sampleRatio float64
+	syncExport  bool
+
+	tp *sdktrace.TracerProvider
+}
+
+// New registers a global tracer provider sending spans to exporter, e.g. an
+// OTLP exporter or tracetest.InMemoryExporter in tests, and the W3C trace
+// context propagator. Root spans are sampled by ratio, child spans follow
+// their parent.
+func New(exporter sdktrace.SpanExporter, opts ...Option) (*Provider, error) {
+	p := &Provider{
+		serviceName: _defaultServiceName,
+		sampleRatio: _defaultSampleRatio,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(p.serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing - New - resource.Merge: %w", err)
+	}
+
+	processor := sdktrace.NewBatchSpanProcessor(exporter)
+	if p.syncExport {
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	}
+
+	p.tp = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(p.sampleRatio))),
+	)
+
+	otel.SetTracerProvider(p.tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return p, nil
+}
+
+// NewOTLPExporter creates the exporter of spans to the OTLP/HTTP collector
+// at endpoint given as host:port.
+func NewOTLPExporter(ctx context.Context, endpoint string, insecure bool) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("tracing - NewOTLPExporter - otlptracehttp.New: %w", err)
+	}
+
+	return exporter, nil
+}
+
+// Shutdown flushes buffered spans and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return p.tp.Shutdown(ctx)
+}